@@ -9,20 +9,33 @@ import (
 )
 
 // BuyerClient provides access to the Tradera Buyer API.
-// Requires user authentication (UserID and Token in config).
+// Requires user authentication (UserID and Token in config, a CredentialProvider, or WithUser per call).
 type BuyerClient struct {
 	client  *Client
 	service buyer.BuyerServiceSoap
 }
 
 func newBuyerClient(c *Client) *BuyerClient {
-	soapClient := c.createSOAPClient(BuyerServiceURL)
+	soapClient := c.createSOAPClient(BuyerServiceURL, c.configAuth())
 	return &BuyerClient{
 		client:  c,
 		service: buyer.NewBuyerServiceSoap(soapClient),
 	}
 }
 
+// op returns the operation descriptor for an action of this service.
+func (c *BuyerClient) op(action string, request any, opts []CallOption) Call {
+	return Call{Service: ServiceBuyer, Action: action, Request: request, opts: opts, userAuth: true}
+}
+
+// serviceFor returns the service to call, honoring a per-call user from WithUser.
+func (c *BuyerClient) serviceFor(ctx context.Context) buyer.BuyerServiceSoap {
+	if auth := authFromContext(ctx); auth != nil {
+		return buyer.NewBuyerServiceSoap(c.client.createSOAPClient(BuyerServiceURL, auth))
+	}
+	return c.service
+}
+
 // BuyResult represents the result of a buy operation.
 type BuyResult struct {
 	NextBid int32
@@ -30,16 +43,14 @@ type BuyResult struct {
 }
 
 // Buy purchases an item (Buy It Now).
-func (c *BuyerClient) Buy(ctx context.Context, itemID int32, buyAmount int32) (*BuyResult, error) {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return nil, err
+func (c *BuyerClient) Buy(ctx context.Context, itemID int32, buyAmount int32, opts ...CallOption) (*BuyResult, error) {
+	request := &buyer.Buy{
+		ItemId:    itemID,
+		BuyAmount: buyAmount,
 	}
 
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*buyer.BuyResponse, error) {
-		return c.service.BuyContext(ctx, &buyer.Buy{
-			ItemId:    itemID,
-			BuyAmount: buyAmount,
-		})
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("Buy", request, opts).withItem(itemID), func(ctx context.Context) (*buyer.BuyResponse, error) {
+		return c.serviceFor(ctx).BuyContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -74,11 +85,7 @@ type MemorylistItem struct {
 }
 
 // GetMemorylistItems retrieves items from the user's memory list (watchlist).
-func (c *BuyerClient) GetMemorylistItems(ctx context.Context, filterActive *string, minEndDate, maxEndDate *time.Time) ([]*MemorylistItem, error) {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return nil, err
-	}
-
+func (c *BuyerClient) GetMemorylistItems(ctx context.Context, filterActive *string, minEndDate, maxEndDate *time.Time, opts ...CallOption) ([]*MemorylistItem, error) {
 	req := &buyer.GetMemorylistItems{}
 
 	if filterActive != nil {
@@ -96,8 +103,8 @@ func (c *BuyerClient) GetMemorylistItems(ctx context.Context, filterActive *stri
 		req.MaxEndDate = &dt
 	}
 
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*buyer.GetMemorylistItemsResponse, error) {
-		return c.service.GetMemorylistItemsContext(ctx, req)
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetMemorylistItems", req, opts), func(ctx context.Context) (*buyer.GetMemorylistItemsResponse, error) {
+		return c.serviceFor(ctx).GetMemorylistItemsContext(ctx, req)
 	})
 	if err != nil {
 		return nil, err
@@ -131,29 +138,25 @@ func (c *BuyerClient) GetMemorylistItems(ctx context.Context, filterActive *stri
 }
 
 // AddToMemorylist adds items to the user's memory list (watchlist).
-func (c *BuyerClient) AddToMemorylist(ctx context.Context, itemIDs []int32) error {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return err
+func (c *BuyerClient) AddToMemorylist(ctx context.Context, itemIDs []int32, opts ...CallOption) error {
+	request := &buyer.AddToMemorylist{
+		ItemIds: &buyer.ArrayOfInt{},
 	}
 
-	return c.client.executeWithMiddleware(ctx, func() error {
-		_, err := c.service.AddToMemorylistContext(ctx, &buyer.AddToMemorylist{
-			ItemIds: &buyer.ArrayOfInt{},
-		})
+	return c.client.executeWithMiddleware(ctx, c.op("AddToMemorylist", request, opts), func(ctx context.Context) error {
+		_, err := c.serviceFor(ctx).AddToMemorylistContext(ctx, request)
 		return err
 	})
 }
 
 // RemoveFromMemorylist removes items from the user's memory list (watchlist).
-func (c *BuyerClient) RemoveFromMemorylist(ctx context.Context, itemIDs []int32) error {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return err
+func (c *BuyerClient) RemoveFromMemorylist(ctx context.Context, itemIDs []int32, opts ...CallOption) error {
+	request := &buyer.RemoveFromMemorylist{
+		ItemIds: &buyer.ArrayOfInt{},
 	}
 
-	return c.client.executeWithMiddleware(ctx, func() error {
-		_, err := c.service.RemoveFromMemorylistContext(ctx, &buyer.RemoveFromMemorylist{
-			ItemIds: &buyer.ArrayOfInt{},
-		})
+	return c.client.executeWithMiddleware(ctx, c.op("RemoveFromMemorylist", request, opts), func(ctx context.Context) error {
+		_, err := c.serviceFor(ctx).RemoveFromMemorylistContext(ctx, request)
 		return err
 	})
 }
@@ -176,11 +179,7 @@ type BuyerTransaction struct {
 }
 
 // GetBuyerTransactions retrieves transactions for the authenticated buyer.
-func (c *BuyerClient) GetBuyerTransactions(ctx context.Context, minDate, maxDate *time.Time) ([]*BuyerTransaction, error) {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return nil, err
-	}
-
+func (c *BuyerClient) GetBuyerTransactions(ctx context.Context, minDate, maxDate *time.Time, opts ...CallOption) ([]*BuyerTransaction, error) {
 	req := &buyer.GetBuyerTransactions{
 		Request: &buyer.GetBuyerTransactionsRequest{},
 	}
@@ -195,8 +194,8 @@ func (c *BuyerClient) GetBuyerTransactions(ctx context.Context, minDate, maxDate
 		req.Request.MaxTransactionDate = &dt
 	}
 
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*buyer.GetBuyerTransactionsResponse, error) {
-		return c.service.GetBuyerTransactionsContext(ctx, req)
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetBuyerTransactions", req, opts), func(ctx context.Context) (*buyer.GetBuyerTransactionsResponse, error) {
+		return c.serviceFor(ctx).GetBuyerTransactionsContext(ctx, req)
 	})
 	if err != nil {
 		return nil, err
@@ -255,11 +254,7 @@ type AuctionBiddingInfo struct {
 }
 
 // GetBiddingInfo retrieves bidding information for items the user has bid on.
-func (c *BuyerClient) GetBiddingInfo(ctx context.Context, minDate, maxDate *time.Time, filterActive, filterLeading *string, includeHidden *bool) ([]*AuctionBiddingInfo, error) {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return nil, err
-	}
-
+func (c *BuyerClient) GetBiddingInfo(ctx context.Context, minDate, maxDate *time.Time, filterActive, filterLeading *string, includeHidden *bool, opts ...CallOption) ([]*AuctionBiddingInfo, error) {
 	req := &buyer.GetBiddingInfo{
 		Request: &buyer.GetBiddingInfoRequest{},
 	}
@@ -286,8 +281,8 @@ func (c *BuyerClient) GetBiddingInfo(ctx context.Context, minDate, maxDate *time
 
 	req.Request.IncludeHidden = includeHidden
 
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*buyer.GetBiddingInfoResponse, error) {
-		return c.service.GetBiddingInfoContext(ctx, req)
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetBiddingInfo", req, opts), func(ctx context.Context) (*buyer.GetBiddingInfoResponse, error) {
+		return c.serviceFor(ctx).GetBiddingInfoContext(ctx, req)
 	})
 	if err != nil {
 		return nil, err
@@ -343,15 +338,13 @@ type DetailedSellerRating struct {
 }
 
 // GetSellerInfo retrieves public information about a seller.
-func (c *BuyerClient) GetSellerInfo(ctx context.Context, userID int32) (*SellerInfo, error) {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return nil, err
+func (c *BuyerClient) GetSellerInfo(ctx context.Context, userID int32, opts ...CallOption) (*SellerInfo, error) {
+	request := &buyer.GetSellerInfo{
+		UserId: userID,
 	}
 
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*buyer.GetSellerInfoResponse, error) {
-		return c.service.GetSellerInfoContext(ctx, &buyer.GetSellerInfo{
-			UserId: userID,
-		})
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetSellerInfo", request, opts).withUser(userID), func(ctx context.Context) (*buyer.GetSellerInfoResponse, error) {
+		return c.serviceFor(ctx).GetSellerInfoContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -387,11 +380,7 @@ func (c *BuyerClient) GetSellerInfo(ctx context.Context, userID int32) (*SellerI
 }
 
 // MarkTransactionsPaid marks transactions as paid by the buyer.
-func (c *BuyerClient) MarkTransactionsPaid(ctx context.Context, transactionIDs []int32, markedAsPaid bool) error {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return err
-	}
-
+func (c *BuyerClient) MarkTransactionsPaid(ctx context.Context, transactionIDs []int32, markedAsPaid bool, opts ...CallOption) error {
 	requests := make([]*buyer.MarkTransactionsPaidRequest, len(transactionIDs))
 	for i, id := range transactionIDs {
 		requests[i] = &buyer.MarkTransactionsPaidRequest{
@@ -400,28 +389,28 @@ func (c *BuyerClient) MarkTransactionsPaid(ctx context.Context, transactionIDs [
 		}
 	}
 
-	return c.client.executeWithMiddleware(ctx, func() error {
-		_, err := c.service.MarkTransactionsPaidContext(ctx, &buyer.MarkTransactionsPaid{
-			Request: &buyer.ArrayOfMarkTransactionsPaidRequest{
-				MarkTransactionsPaidRequest: requests,
-			},
-		})
+	request := &buyer.MarkTransactionsPaid{
+		Request: &buyer.ArrayOfMarkTransactionsPaidRequest{
+			MarkTransactionsPaidRequest: requests,
+		},
+	}
+
+	return c.client.executeWithMiddleware(ctx, c.op("MarkTransactionsPaid", request, opts), func(ctx context.Context) error {
+		_, err := c.serviceFor(ctx).MarkTransactionsPaidContext(ctx, request)
 		return err
 	})
 }
 
 // SendQuestionToSeller sends a question to the seller of an item.
-func (c *BuyerClient) SendQuestionToSeller(ctx context.Context, itemID int32, question string, sendCopyToSender bool) (string, error) {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return "", err
+func (c *BuyerClient) SendQuestionToSeller(ctx context.Context, itemID int32, question string, sendCopyToSender bool, opts ...CallOption) (string, error) {
+	request := &buyer.SendQuestionToSeller{
+		ItemId:           itemID,
+		Question:         question,
+		SendCopyToSender: sendCopyToSender,
 	}
 
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*buyer.SendQuestionToSellerResponse, error) {
-		return c.service.SendQuestionToSellerContext(ctx, &buyer.SendQuestionToSeller{
-			ItemId:           itemID,
-			Question:         question,
-			SendCopyToSender: sendCopyToSender,
-		})
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("SendQuestionToSeller", request, opts).withItem(itemID), func(ctx context.Context) (*buyer.SendQuestionToSellerResponse, error) {
+		return c.serviceFor(ctx).SendQuestionToSellerContext(ctx, request)
 	})
 	if err != nil {
 		return "", err
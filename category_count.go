@@ -0,0 +1,89 @@
+package tradera
+
+import "context"
+
+// CountedCategory is a category annotated with the number of items a
+// search found in it.
+type CountedCategory struct {
+	*Category
+
+	NoOfItemsInCategory                  int32
+	NoOfItemsInCategoryIncludingChildren int32
+
+	// Children holds the counted subcategories; with pruning, only those
+	// with items.
+	Children []*CountedCategory
+}
+
+// WithCounts returns the tree annotated with the item counts of a
+// SearchCategoryCount result. Categories missing from counts have no items
+// of their own; their totals include their subcategories. With pruneEmpty,
+// categories without items, including subcategories, are left out.
+//
+// The tree itself is not modified.
+func (t *CategoryTree) WithCounts(counts *CategoryCountResult, pruneEmpty bool) []*CountedCategory {
+	byID := make(map[int32]*SearchCategory)
+	var index func(cats []*SearchCategory)
+	index = func(cats []*SearchCategory) {
+		for _, cat := range cats {
+			if cat == nil {
+				continue
+			}
+			byID[cat.ID] = cat
+			index(cat.ChildCategories)
+		}
+	}
+	if counts != nil {
+		index(counts.Categories)
+	}
+
+	var annotate func(cats []*Category) []*CountedCategory
+	annotate = func(cats []*Category) []*CountedCategory {
+		var counted []*CountedCategory
+		for _, cat := range cats {
+			if cat == nil {
+				continue
+			}
+
+			cc := &CountedCategory{Category: cat, Children: annotate(cat.Children)}
+			if count, ok := byID[cat.ID]; ok {
+				cc.NoOfItemsInCategory = count.NoOfItemsInCategory
+				cc.NoOfItemsInCategoryIncludingChildren = count.NoOfItemsInCategoryIncludingChildren
+			} else {
+				cc.NoOfItemsInCategoryIncludingChildren = cc.childTotal()
+			}
+
+			if pruneEmpty && cc.NoOfItemsInCategoryIncludingChildren == 0 && cc.NoOfItemsInCategory == 0 {
+				continue
+			}
+			counted = append(counted, cc)
+		}
+		return counted
+	}
+	return annotate(t.Roots)
+}
+
+// childTotal returns the items in the subcategories.
+func (c *CountedCategory) childTotal() int32 {
+	var total int32
+	for _, child := range c.Children {
+		total += child.NoOfItemsInCategoryIncludingChildren
+	}
+	return total
+}
+
+// CategoryFacets returns the category tree annotated with the item counts
+// for req, e.g. for a category sidebar next to the results of the same
+// search; see CategoryTree.WithCounts. The tree comes from
+// PublicClient.GetCategories and is cached when caching is enabled.
+func (c *SearchClient) CategoryFacets(ctx context.Context, req CategoryCountRequest, pruneEmpty bool, opts ...CallOption) ([]*CountedCategory, error) {
+	tree, err := c.client.Public().GetCategories(ctx, opts...)
+	if err != nil {
+		return nil, err
+	}
+	counts, err := c.SearchCategoryCount(ctx, req, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return tree.WithCounts(counts, pruneEmpty), nil
+}
@@ -0,0 +1,232 @@
+package tradera
+
+import (
+	"bytes"
+	"encoding/gob"
+	"iter"
+	"slices"
+	"strings"
+	"unicode"
+)
+
+// breadcrumbSeparator separates the category names of a breadcrumb.
+const breadcrumbSeparator = " > "
+
+// CategoryTree is the Tradera category hierarchy with an index by ID.
+// It is shared through the client cache and must not be modified.
+type CategoryTree struct {
+	Roots []*Category
+
+	byID map[int32]*Category
+}
+
+// NewCategoryTree indexes the categories below roots and links each one to
+// its parent.
+func NewCategoryTree(roots []*Category) *CategoryTree {
+	t := &CategoryTree{Roots: roots, byID: make(map[int32]*Category)}
+	var index func(parent *Category, cats []*Category)
+	index = func(parent *Category, cats []*Category) {
+		for _, cat := range cats {
+			if cat == nil {
+				continue
+			}
+			cat.parent = parent
+			t.byID[cat.ID] = cat
+			index(cat, cat.Children)
+		}
+	}
+	index(nil, roots)
+	return t
+}
+
+// Len returns the number of categories in the tree.
+func (t *CategoryTree) Len() int {
+	return len(t.byID)
+}
+
+// Get returns the category with the given ID.
+func (t *CategoryTree) Get(id int32) (*Category, bool) {
+	cat, ok := t.byID[id]
+	return cat, ok
+}
+
+// Path returns the categories from the root down to the category with the
+// given ID, or nil if there is no such category.
+func (t *CategoryTree) Path(id int32) []*Category {
+	cat, ok := t.byID[id]
+	if !ok {
+		return nil
+	}
+	var path []*Category
+	for ; cat != nil; cat = cat.parent {
+		path = append(path, cat)
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// Breadcrumb returns the names along the path to the category with the
+// given ID, such as "Hobby > Kameror > Analoga", or "" if there is no such
+// category.
+func (t *CategoryTree) Breadcrumb(id int32) string {
+	path := t.Path(id)
+	names := make([]string, len(path))
+	for i, cat := range path {
+		names[i] = cat.Name
+	}
+	return strings.Join(names, breadcrumbSeparator)
+}
+
+// Leaves returns the categories without children, in tree order.
+func (t *CategoryTree) Leaves() []*Category {
+	var leaves []*Category
+	for cat := range t.walk(0) {
+		if cat.IsLeaf() {
+			leaves = append(leaves, cat)
+		}
+	}
+	return leaves
+}
+
+// Flatten returns the categories in tree order, parents before children,
+// down to maxDepth levels (1 = roots only, 0 = all).
+func (t *CategoryTree) Flatten(maxDepth int) []*Category {
+	return slices.Collect(t.walk(maxDepth))
+}
+
+// Search returns the categories whose name contains query, ignoring case
+// and accents, sorted by name in Swedish order. As in Swedish, å, ä and ö
+// are letters of their own: "a" does not match "Ålar", but "cafe" matches
+// "Café".
+func (t *CategoryTree) Search(query string) []*Category {
+	needle := swedishFold(query)
+	var found []*Category
+	for cat := range t.walk(0) {
+		if strings.Contains(swedishFold(cat.Name), needle) {
+			found = append(found, cat)
+		}
+	}
+	slices.SortStableFunc(found, func(a, b *Category) int {
+		return compareSwedish(a.Name, b.Name)
+	})
+	return found
+}
+
+// walk yields the categories in tree order down to maxDepth levels (0 = all).
+func (t *CategoryTree) walk(maxDepth int) iter.Seq[*Category] {
+	return func(yield func(*Category) bool) {
+		var visit func(cats []*Category, depth int) bool
+		visit = func(cats []*Category, depth int) bool {
+			if maxDepth > 0 && depth > maxDepth {
+				return true
+			}
+			for _, cat := range cats {
+				if cat == nil {
+					continue
+				}
+				if !yield(cat) || !visit(cat.Children, depth+1) {
+					return false
+				}
+			}
+			return true
+		}
+		visit(t.Roots, 1)
+	}
+}
+
+// GobEncode implements gob.GobEncoder so a tree can be stored in a
+// middleware.DiskCache. Only the categories are stored.
+func (t *CategoryTree) GobEncode() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(t.Roots); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder, rebuilding the index.
+func (t *CategoryTree) GobDecode(data []byte) error {
+	var roots []*Category
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&roots); err != nil {
+		return err
+	}
+	*t = *NewCategoryTree(roots)
+	return nil
+}
+
+// Parent returns the parent category, or nil for a root category or one
+// that is not part of a CategoryTree.
+func (c *Category) Parent() *Category {
+	return c.parent
+}
+
+// IsLeaf reports whether the category has no subcategories.
+func (c *Category) IsLeaf() bool {
+	return len(c.Children) == 0
+}
+
+// Depth returns the level of the category in its tree, 1 for a root.
+func (c *Category) Depth() int {
+	depth := 0
+	for cat := c; cat != nil; cat = cat.parent {
+		depth++
+	}
+	return depth
+}
+
+// swedishFold lowercases s and strips accents that are not letters of their
+// own in Swedish, mapping Danish and Norwegian æ and ø to ä and ö.
+func swedishFold(s string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToLower(r)
+		switch r {
+		case 'á', 'à', 'â', 'ã':
+			return 'a'
+		case 'é', 'è', 'ê', 'ë':
+			return 'e'
+		case 'í', 'ì', 'î', 'ï':
+			return 'i'
+		case 'ó', 'ò', 'ô', 'õ':
+			return 'o'
+		case 'ú', 'ù', 'û':
+			return 'u'
+		case 'ü':
+			return 'y'
+		case 'ç':
+			return 'c'
+		case 'ñ':
+			return 'n'
+		case 'æ':
+			return 'ä'
+		case 'ø':
+			return 'ö'
+		}
+		return r
+	}, s)
+}
+
+// swedishOrder returns the sort weight of a folded rune, placing å, ä and ö
+// after z as in the Swedish alphabet.
+func swedishOrder(r rune) rune {
+	switch r {
+	case 'å':
+		return 'z' + 1
+	case 'ä':
+		return 'z' + 2
+	case 'ö':
+		return 'z' + 3
+	}
+	return r
+}
+
+// compareSwedish compares a and b in Swedish alphabetical order, ignoring
+// case and accents.
+func compareSwedish(a, b string) int {
+	ra, rb := []rune(swedishFold(a)), []rune(swedishFold(b))
+	for i := range min(len(ra), len(rb)) {
+		if c := swedishOrder(ra[i]) - swedishOrder(rb[i]); c != 0 {
+			return int(c)
+		}
+	}
+	return len(ra) - len(rb)
+}
@@ -1,6 +1,14 @@
 package tradera
 
-import "time"
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/SebbeJohansson/tradera-go-client/middleware"
+)
 
 // Config holds the configuration for the Tradera API client.
 type Config struct {
@@ -19,9 +27,44 @@ type Config struct {
 	// Obtain via PublicClient.FetchToken()
 	Token string
 
+	// TokenExpires is when Token expires (optional). Calls needing user auth
+	// fail with ErrTokenExpired once it has passed.
+	TokenExpires time.Time
+
+	// OnTokenExpiring is called once per token when a call uses a token that
+	// expires within TokenExpiryWarning, so the user can re-authorize in time
+	// (optional). It runs on the calling goroutine and should return quickly.
+	OnTokenExpiring func(ctx context.Context, creds Credentials)
+
+	// TokenExpiryWarning is how long before expiry OnTokenExpiring is called
+	// (default: 7 days).
+	TokenExpiryWarning time.Duration
+
+	// CredentialProvider resolves UserID and Token per call for the
+	// Restricted, Order and Buyer services (optional). It takes precedence
+	// over UserID and Token, which still apply to the other services.
+	CredentialProvider CredentialProvider
+
 	// RateLimit is the maximum number of requests per second (0 = disabled)
 	RateLimit float64
 
+	// Limiter overrides the rate limiter (optional).
+	// Use middleware.NewFileLimiter to share one limit between processes on
+	// the same host. If nil and RateLimit > 0, an in-process
+	// middleware.RateLimiter is used.
+	Limiter middleware.Limiter
+
+	// RateLimitMinShare guarantees priority lanes a minimum fraction (0-1) of
+	// the rate limit while they have waiting requests (optional).
+	// Without it, higher-priority requests are always served first.
+	RateLimitMinShare map[middleware.Priority]float64
+
+	// AdaptiveRateLimit enables adaptive rate limiting (optional, requires RateLimit > 0).
+	// The rate starts at RateLimit, is lowered when Tradera throttles
+	// (ErrRateLimited, HTTP 429/503) and raised again after sustained success,
+	// staying within MinRate and MaxRate.
+	AdaptiveRateLimit *middleware.AdaptiveConfig
+
 	// RetryEnabled enables automatic retry with exponential backoff
 	RetryEnabled bool
 
@@ -35,8 +78,63 @@ type Config struct {
 	// Useful for caching relatively static data like categories
 	CacheTTL time.Duration
 
+	// CacheStore overrides the cache backend (optional).
+	// Use middleware.NewLRUCache for a bounded cache or middleware.NewDiskCache
+	// for one that survives restarts. If nil and CacheTTL > 0, an unbounded
+	// in-memory cache is used. The client logs values a DiskCache without an
+	// OnError handler cannot store with Logger; the DiskCache is not modified.
+	CacheStore middleware.CacheStore
+
+	// CacheStaleWhileRevalidate is how long after CacheTTL an expired entry may
+	// still be served while a single background refresh runs (0 = disabled).
+	CacheStaleWhileRevalidate time.Duration
+
+	// Quotas limits the number of calls per rolling window (optional).
+	// Keys are service names (ServiceSearch, ServicePublic, ...) or
+	// middleware.QuotaAllServices for a limit across all services.
+	// Calls over budget fail with ErrQuotaExhausted.
+	Quotas map[string]middleware.QuotaLimit
+
+	// QuotaStore persists quota counters across restarts (optional).
+	// If nil, counters are kept in memory.
+	QuotaStore middleware.QuotaStore
+
+	// Tracer receives a span for every API operation (optional).
+	// If nil, tracing is disabled.
+	Tracer Tracer
+
+	// Metrics receives request, retry, cache, rate limiter and quota
+	// measurements (optional). Use NewMetricsCollector for a built-in
+	// collector with a Prometheus handler.
+	Metrics Metrics
+
+	// Logger receives a debug-level record of every operation (optional).
+	// Secrets (AppKey, Token, SecretKey) are always redacted.
+	Logger *slog.Logger
+
+	// LogEnvelopes additionally logs the full request and response SOAP
+	// envelopes at debug level. Requires Logger.
+	LogEnvelopes bool
+
+	// Interceptors replaces the chain every API call runs through (optional).
+	// The first interceptor is outermost. If nil, DefaultInterceptors is used;
+	// include it in the slice to keep caching, rate limiting, retry and quotas.
+	Interceptors []Interceptor
+
+	// Transport is the HTTP transport for SOAP requests (optional).
+	// If nil, http.DefaultTransport is used.
+	Transport http.RoundTripper
+
+	// TransportHooks wrap Transport and can inspect or rewrite raw HTTP
+	// requests and responses (optional). The first hook is outermost.
+	TransportHooks []TransportHook
+
 	// Timeout is the default timeout for API requests (default: 30s)
 	Timeout time.Duration
+
+	// BaseURL overrides the address of the Tradera API, e.g. to point the
+	// client at a test server (default: DefaultBaseURL).
+	BaseURL string
 }
 
 // DefaultConfig returns a Config with sensible defaults.
@@ -60,12 +158,51 @@ func (c Config) WithUserAuth(userID int, token string) Config {
 	return c
 }
 
+// WithTokenExpiry returns a copy of the config with the expiry of Token set.
+func (c Config) WithTokenExpiry(expires time.Time) Config {
+	c.TokenExpires = expires
+	return c
+}
+
+// WithTokenExpiryWarning returns a copy of the config calling fn when a
+// token used for a call expires within the given duration.
+func (c Config) WithTokenExpiryWarning(within time.Duration, fn func(ctx context.Context, creds Credentials)) Config {
+	c.TokenExpiryWarning = within
+	c.OnTokenExpiring = fn
+	return c
+}
+
+// WithCredentialProvider returns a copy of the config resolving user
+// authentication per call from provider.
+func (c Config) WithCredentialProvider(provider CredentialProvider) Config {
+	c.CredentialProvider = provider
+	return c
+}
+
 // WithRateLimit returns a copy of the config with rate limiting enabled.
 func (c Config) WithRateLimit(requestsPerSecond float64) Config {
 	c.RateLimit = requestsPerSecond
 	return c
 }
 
+// WithLimiter returns a copy of the config using the given rate limiter.
+func (c Config) WithLimiter(limiter middleware.Limiter) Config {
+	c.Limiter = limiter
+	return c
+}
+
+// WithAdaptiveRateLimit returns a copy of the config with adaptive rate limiting
+// starting at requestsPerSecond and kept within [minRate, maxRate]. A maxRate
+// of 0 means requestsPerSecond and a minRate of 0 means 10% of maxRate.
+func (c Config) WithAdaptiveRateLimit(requestsPerSecond, minRate, maxRate float64) Config {
+	c.RateLimit = requestsPerSecond
+	c.AdaptiveRateLimit = &middleware.AdaptiveConfig{
+		MinRate: minRate,
+		MaxRate: maxRate,
+	}
+	return c
+}
+
 // WithRetry returns a copy of the config with retry enabled.
 func (c Config) WithRetry(maxRetries int, baseDelay time.Duration) Config {
 	c.RetryEnabled = true
@@ -80,12 +217,84 @@ func (c Config) WithCache(ttl time.Duration) Config {
 	return c
 }
 
+// WithCacheStore returns a copy of the config using the given cache backend.
+func (c Config) WithCacheStore(store middleware.CacheStore) Config {
+	c.CacheStore = store
+	return c
+}
+
+// WithStaleWhileRevalidate returns a copy of the config that serves expired
+// cache entries for up to window while they are refreshed in the background.
+func (c Config) WithStaleWhileRevalidate(window time.Duration) Config {
+	c.CacheStaleWhileRevalidate = window
+	return c
+}
+
+// WithQuota returns a copy of the config with a call quota for service.
+// The window defaults to 24 hours; reserved calls are kept for high-priority requests.
+func (c Config) WithQuota(service string, limit int, reserved int) Config {
+	quotas := make(map[string]middleware.QuotaLimit, len(c.Quotas)+1)
+	for k, v := range c.Quotas {
+		quotas[k] = v
+	}
+	quotas[service] = middleware.QuotaLimit{Limit: limit, Reserved: reserved}
+	c.Quotas = quotas
+	return c
+}
+
+// WithTracer returns a copy of the config with the given tracer.
+func (c Config) WithTracer(tracer Tracer) Config {
+	c.Tracer = tracer
+	return c
+}
+
+// WithMetrics returns a copy of the config with the given metrics hooks.
+func (c Config) WithMetrics(metrics Metrics) Config {
+	c.Metrics = metrics
+	return c
+}
+
+// WithInterceptors returns a copy of the config with interceptors appended
+// to the chain, which starts out as DefaultInterceptors. Appended
+// interceptors run innermost and so see every attempt.
+func (c Config) WithInterceptors(interceptors ...Interceptor) Config {
+	chain := c.Interceptors
+	if chain == nil {
+		chain = DefaultInterceptors()
+	}
+	c.Interceptors = append(chain[:len(chain):len(chain)], interceptors...)
+	return c
+}
+
+// WithTransportHook returns a copy of the config with hook added innermost
+// to the HTTP transport hooks.
+func (c Config) WithTransportHook(hook TransportHook) Config {
+	c.TransportHooks = append(c.TransportHooks[:len(c.TransportHooks):len(c.TransportHooks)], hook)
+	return c
+}
+
+// WithLogger returns a copy of the config with the given logger.
+// If logEnvelopes is true, redacted SOAP envelopes are logged as well.
+func (c Config) WithLogger(logger *slog.Logger, logEnvelopes bool) Config {
+	c.Logger = logger
+	c.LogEnvelopes = logEnvelopes
+	return c
+}
+
 // WithTimeout returns a copy of the config with the specified timeout.
 func (c Config) WithTimeout(timeout time.Duration) Config {
 	c.Timeout = timeout
 	return c
 }
 
+// baseURL returns the API address without a trailing slash.
+func (c Config) baseURL() string {
+	if c.BaseURL == "" {
+		return DefaultBaseURL
+	}
+	return strings.TrimSuffix(c.BaseURL, "/")
+}
+
 // HasUserAuth returns true if user authentication is configured.
 func (c Config) HasUserAuth() bool {
 	return c.UserID > 0 && c.Token != ""
@@ -0,0 +1,248 @@
+package tradera
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// CredentialStore keeps user credentials by tenant, e.g. the tokens obtained
+// through TokenLogin. Implementations must be safe for concurrent use.
+type CredentialStore interface {
+	// Get returns the credentials of tenant, with ok false if there are none.
+	Get(tenant string) (creds Credentials, ok bool, err error)
+
+	// Put stores the credentials of tenant, replacing any previous ones.
+	Put(tenant string, creds Credentials) error
+
+	// Delete removes the credentials of tenant.
+	Delete(tenant string) error
+
+	// All returns the credentials of every tenant.
+	All() (map[string]Credentials, error)
+}
+
+// NewStoreCredentialProvider returns a CredentialProvider that looks up the
+// tenant set with WithTenant in store.
+func NewStoreCredentialProvider(store CredentialStore) CredentialProvider {
+	return CredentialProviderFunc(func(ctx context.Context) (Credentials, error) {
+		tenant, ok := TenantFromContext(ctx)
+		if !ok {
+			return Credentials{}, ErrAuthRequired
+		}
+		creds, _, err := store.Get(tenant)
+		return creds, err
+	})
+}
+
+// ExpiringCredentials returns the credentials in store that expire within
+// the given duration, including already expired ones. Credentials without a
+// known expiry are skipped. Use it to ask users to re-authorize in time.
+func ExpiringCredentials(store CredentialStore, within time.Duration) (map[string]Credentials, error) {
+	all, err := store.All()
+	if err != nil {
+		return nil, err
+	}
+
+	deadline := time.Now().Add(within)
+	expiring := make(map[string]Credentials)
+	for tenant, creds := range all {
+		if !creds.Expires.IsZero() && creds.Expires.Before(deadline) {
+			expiring[tenant] = creds
+		}
+	}
+	return expiring, nil
+}
+
+// MemoryCredentialStore keeps credentials in memory.
+type MemoryCredentialStore struct {
+	creds map[string]Credentials
+	mu    sync.RWMutex
+}
+
+// NewMemoryCredentialStore creates an empty in-memory credential store.
+func NewMemoryCredentialStore() *MemoryCredentialStore {
+	return &MemoryCredentialStore{creds: make(map[string]Credentials)}
+}
+
+// Get returns the credentials of tenant.
+func (m *MemoryCredentialStore) Get(tenant string) (Credentials, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	creds, ok := m.creds[tenant]
+	return creds, ok, nil
+}
+
+// Put stores the credentials of tenant.
+func (m *MemoryCredentialStore) Put(tenant string, creds Credentials) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.creds[tenant] = creds
+	return nil
+}
+
+// Delete removes the credentials of tenant.
+func (m *MemoryCredentialStore) Delete(tenant string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.creds, tenant)
+	return nil
+}
+
+// All returns the credentials of every tenant.
+func (m *MemoryCredentialStore) All() (map[string]Credentials, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	all := make(map[string]Credentials, len(m.creds))
+	for tenant, creds := range m.creds {
+		all[tenant] = creds
+	}
+	return all, nil
+}
+
+// FileCredentialStore persists credentials in a single file encrypted with
+// AES-GCM, so tokens are never written to disk in clear text.
+type FileCredentialStore struct {
+	path string
+	aead cipher.AEAD
+	mu   sync.Mutex
+}
+
+// NewFileCredentialStore creates a credential store backed by the file at
+// path. key must be 16, 24 or 32 bytes long (AES-128, AES-192 or AES-256)
+// and must be kept secret. The file is created on the first write.
+func NewFileCredentialStore(path string, key []byte) (*FileCredentialStore, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("tradera: credential store key: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &FileCredentialStore{path: path, aead: aead}, nil
+}
+
+// Get returns the credentials of tenant.
+func (f *FileCredentialStore) Get(tenant string) (Credentials, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all, err := f.read()
+	if err != nil {
+		return Credentials{}, false, err
+	}
+	creds, ok := all[tenant]
+	return creds, ok, nil
+}
+
+// Put stores the credentials of tenant.
+func (f *FileCredentialStore) Put(tenant string, creds Credentials) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all, err := f.read()
+	if err != nil {
+		return err
+	}
+	all[tenant] = creds
+	return f.write(all)
+}
+
+// Delete removes the credentials of tenant.
+func (f *FileCredentialStore) Delete(tenant string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := all[tenant]; !ok {
+		return nil
+	}
+	delete(all, tenant)
+	return f.write(all)
+}
+
+// All returns the credentials of every tenant.
+func (f *FileCredentialStore) All() (map[string]Credentials, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	return f.read()
+}
+
+// read decrypts all credentials from the file.
+// Must be called with mutex held.
+func (f *FileCredentialStore) read() (map[string]Credentials, error) {
+	all := make(map[string]Credentials)
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := f.aead.NonceSize()
+	if len(data) < nonceSize {
+		return nil, fmt.Errorf("tradera: reading credential file %s: file too short", f.path)
+	}
+	plaintext, err := f.aead.Open(nil, data[:nonceSize], data[nonceSize:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("tradera: reading credential file %s: wrong key or corrupted file", f.path)
+	}
+
+	if err := json.Unmarshal(plaintext, &all); err != nil {
+		return nil, fmt.Errorf("tradera: reading credential file %s: %w", f.path, err)
+	}
+	return all, nil
+}
+
+// write encrypts all credentials to the file.
+// Must be called with mutex held.
+func (f *FileCredentialStore) write(all map[string]Credentials) error {
+	plaintext, err := json.Marshal(all)
+	if err != nil {
+		return err
+	}
+
+	nonce := make([]byte, f.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return err
+	}
+	return writeFileAtomic(f.path, f.aead.Seal(nonce, nonce, plaintext, nil))
+}
+
+// writeFileAtomic writes data to a temporary file, readable only by the
+// owner, and renames it to path so a crash never leaves a partial file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
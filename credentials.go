@@ -0,0 +1,140 @@
+package tradera
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// defaultTokenExpiryWarning is how long before expiry OnTokenExpiring is called
+// if Config.TokenExpiryWarning is not set.
+const defaultTokenExpiryWarning = 7 * 24 * time.Hour
+
+// Credentials identify the Tradera user a call is made for.
+type Credentials struct {
+	UserID  int
+	Token   string
+	Expires time.Time // zero if unknown
+}
+
+// Valid reports whether both the user ID and the token are set.
+func (c Credentials) Valid() bool {
+	return c.UserID > 0 && c.Token != ""
+}
+
+// Expired reports whether the token is known to have expired.
+func (c Credentials) Expired() bool {
+	return !c.Expires.IsZero() && !time.Now().Before(c.Expires)
+}
+
+// CredentialProvider resolves the user for calls to the Restricted, Order and
+// Buyer services, typically from a tenant set on the context with WithTenant.
+// It lets one Client, with its cache, rate limiter and connection pool, act
+// for many users. Implementations must be safe for concurrent use.
+type CredentialProvider interface {
+	// Credentials returns the user for a call made with ctx.
+	// Returning zero Credentials fails the call with ErrAuthRequired.
+	Credentials(ctx context.Context) (Credentials, error)
+}
+
+// CredentialProviderFunc adapts a function to CredentialProvider.
+type CredentialProviderFunc func(ctx context.Context) (Credentials, error)
+
+// Credentials implements CredentialProvider.
+func (f CredentialProviderFunc) Credentials(ctx context.Context) (Credentials, error) {
+	return f(ctx)
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a context identifying the tenant calls are made for,
+// for use by a CredentialProvider.
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant set with WithTenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
+
+// resolveAuth returns the user authorization for a call: a WithUser or
+// WithUserCredentials option wins, then the credential provider for services
+// requiring user auth, then the config. override is true if the result
+// differs from the config. For services requiring user auth, incomplete
+// credentials fail with ErrAuthRequired and known-expired tokens with
+// ErrTokenExpired before anything is sent.
+func (c *Client) resolveAuth(ctx context.Context, call *Call) (auth *AuthorizationHeader, override bool, err error) {
+	var creds Credentials
+	switch {
+	case call.options.user != nil:
+		creds = *call.options.user
+		override = true
+	case call.userAuth && c.config.CredentialProvider != nil:
+		creds, err = c.config.CredentialProvider.Credentials(ctx)
+		if err != nil {
+			return nil, false, fmt.Errorf("tradera: resolve credentials: %w", err)
+		}
+		override = true
+	case c.config.HasUserAuth():
+		creds = Credentials{UserID: c.config.UserID, Token: c.config.Token, Expires: c.config.TokenExpires}
+	default:
+		return nil, false, nil
+	}
+
+	if call.userAuth {
+		if !creds.Valid() {
+			return nil, false, ErrAuthRequired
+		}
+		if err := c.checkExpiry(ctx, creds); err != nil {
+			return nil, false, err
+		}
+	}
+	return &AuthorizationHeader{UserID: creds.UserID, Token: creds.Token}, override, nil
+}
+
+// tokenKey identifies a token for expiry warnings.
+type tokenKey struct {
+	userID  int
+	expires int64
+}
+
+// checkExpiry fails if creds are expired and calls Config.OnTokenExpiring,
+// once per token, when they are about to expire.
+func (c *Client) checkExpiry(ctx context.Context, creds Credentials) error {
+	if creds.Expires.IsZero() {
+		return nil
+	}
+	if creds.Expired() {
+		return fmt.Errorf("%w: user %d, expired %s", ErrTokenExpired, creds.UserID, creds.Expires.Format(time.RFC3339))
+	}
+
+	if c.config.OnTokenExpiring == nil {
+		return nil
+	}
+	warning := c.config.TokenExpiryWarning
+	if warning <= 0 {
+		warning = defaultTokenExpiryWarning
+	}
+	if time.Until(creds.Expires) <= warning {
+		key := tokenKey{creds.UserID, creds.Expires.UnixNano()}
+		if _, warned := c.warnedTokens.LoadOrStore(key, struct{}{}); !warned {
+			c.pruneWarnedTokens()
+			c.config.OnTokenExpiring(ctx, creds)
+		}
+	}
+	return nil
+}
+
+// pruneWarnedTokens forgets warnings for tokens that have expired, which can
+// no longer be used and are never warned about again.
+func (c *Client) pruneWarnedTokens() {
+	now := time.Now().UnixNano()
+	c.warnedTokens.Range(func(key, _ any) bool {
+		if key.(tokenKey).expires <= now {
+			c.warnedTokens.Delete(key)
+		}
+		return true
+	})
+}
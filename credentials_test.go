@@ -0,0 +1,42 @@
+package tradera_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	tradera "github.com/SebbeJohansson/tradera-go-client"
+)
+
+func TestIncompleteUserOverrideRequiresAuth(t *testing.T) {
+	server := newFakeTradera(t, http.StatusOK, fetchTokenResponse)
+
+	config := tradera.DefaultConfig(1234, "app-key").WithUserAuth(7, "config-token")
+	config.BaseURL = server.URL
+	client, err := tradera.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+
+	tests := []struct {
+		name string
+		opt  tradera.CallOption
+	}{
+		{"no user", tradera.WithUser(0, "user-token")},
+		{"no token", tradera.WithUser(42, "")},
+		{"empty credentials", tradera.WithUserCredentials(tradera.Credentials{})},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := client.Buyer().Buy(context.Background(), 1, 100, tt.opt)
+			if !errors.Is(err, tradera.ErrAuthRequired) {
+				t.Fatalf("Buy: err = %v, want ErrAuthRequired", err)
+			}
+			if server.calls.Load() != 0 {
+				t.Fatal("Buy with incomplete credentials reached the API")
+			}
+		})
+	}
+}
@@ -1,8 +1,16 @@
 package tradera
 
 import (
+	"context"
+	"encoding/xml"
 	"errors"
 	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/SebbeJohansson/tradera-go-client/middleware"
+	"github.com/hooklift/gowsdl/soap"
 )
 
 // Sentinel errors for common error conditions.
@@ -16,6 +24,11 @@ var (
 	// ErrAuthRequired is returned when user authentication is required but not provided.
 	ErrAuthRequired = errors.New("tradera: user authentication required (UserID and Token)")
 
+	// ErrTokenExpired is returned when the user's token has expired, either
+	// according to its known expiry date or as reported by the API.
+	// The user must re-authorize to obtain a new token.
+	ErrTokenExpired = errors.New("tradera: user token expired")
+
 	// ErrRateLimited is returned when the rate limit has been exceeded.
 	ErrRateLimited = errors.New("tradera: rate limit exceeded")
 
@@ -24,8 +37,20 @@ var (
 
 	// ErrNotFound is returned when the requested resource is not found.
 	ErrNotFound = errors.New("tradera: resource not found")
+
+	// ErrInvalidParameter is returned when a request parameter has a value
+	// the API does not accept. Such requests are rejected before being sent.
+	ErrInvalidParameter = errors.New("tradera: invalid parameter")
+
+	// ErrQuotaExhausted is returned when a call would exceed a configured quota.
+	// The returned error is a *QuotaError with the time until the next reset.
+	ErrQuotaExhausted = middleware.ErrQuotaExhausted
 )
 
+// QuotaError is returned when a quota has been used up.
+// It matches ErrQuotaExhausted with errors.Is.
+type QuotaError = middleware.QuotaError
+
 // APIError represents an error returned by the Tradera API.
 type APIError struct {
 	// Code is the error code from the API.
@@ -80,6 +105,43 @@ func (f *SOAPFault) Error() string {
 	return fmt.Sprintf("SOAP fault [%s]: %s", f.FaultCode, f.FaultString)
 }
 
+// mapped returns the fault, wrapped with ErrTokenExpired if it says the
+// user's token has expired.
+func (f *SOAPFault) mapped() error {
+	text := strings.ToLower(f.FaultString + " " + f.Detail)
+	if strings.Contains(text, "expired") &&
+		(strings.Contains(text, "token") || strings.Contains(text, "authoriz")) {
+		return fmt.Errorf("%w: %w", ErrTokenExpired, f)
+	}
+	return f
+}
+
+// parseFault extracts a SOAP fault from a response body, or returns nil if
+// the body holds none.
+func parseFault(body []byte) *SOAPFault {
+	var envelope struct {
+		Body struct {
+			Fault *struct {
+				Code   string `xml:"faultcode"`
+				String string `xml:"faultstring"`
+				Detail struct {
+					Content string `xml:",innerxml"`
+				} `xml:"detail"`
+			} `xml:"Fault"`
+		} `xml:"Body"`
+	}
+	if err := xml.Unmarshal(body, &envelope); err != nil || envelope.Body.Fault == nil {
+		return nil
+	}
+
+	fault := envelope.Body.Fault
+	return &SOAPFault{
+		FaultCode:   fault.Code,
+		FaultString: fault.String,
+		Detail:      strings.TrimSpace(fault.Detail.Content),
+	}
+}
+
 // NetworkError wraps network-related errors.
 type NetworkError struct {
 	Op  string // Operation that failed
@@ -96,6 +158,138 @@ func (e *NetworkError) Unwrap() error {
 	return e.Err
 }
 
+// PageError reports a result page that could not be fetched.
+type PageError struct {
+	Page int32 // page number
+	Err  error // underlying error
+}
+
+// Error implements the error interface.
+func (e *PageError) Error() string {
+	return fmt.Sprintf("tradera: page %d: %v", e.Page, e.Err)
+}
+
+// Unwrap implements errors.Unwrap.
+func (e *PageError) Unwrap() error {
+	return e.Err
+}
+
+// QueryError reports a query of a federated search that failed.
+type QueryError struct {
+	Query int   // index of the request
+	Err   error // underlying error
+}
+
+// Error implements the error interface.
+func (e *QueryError) Error() string {
+	return fmt.Sprintf("tradera: query %d: %v", e.Query, e.Err)
+}
+
+// Unwrap implements errors.Unwrap.
+func (e *QueryError) Unwrap() error {
+	return e.Err
+}
+
+// IsThrottled returns true if the error indicates that Tradera rejected the
+// call for exceeding its limits (ErrRateLimited, or HTTP 429 or 503).
+func IsThrottled(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	if errors.Is(err, ErrRateLimited) {
+		return true
+	}
+
+	var httpErr *soap.HTTPError
+	if errors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests ||
+			httpErr.StatusCode == http.StatusServiceUnavailable
+	}
+
+	return false
+}
+
+// mapError converts errors from the SOAP transport into this package's
+// error types. The original error stays reachable through errors.As where
+// the mapped type wraps it.
+func mapError(action string, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	var httpErr *soap.HTTPError
+	if errors.As(err, &httpErr) {
+		if IsThrottled(httpErr) {
+			return fmt.Errorf("%w: %w", ErrRateLimited, err)
+		}
+		// ASMX services send faults with HTTP 500, which the soap package
+		// reports as an HTTPError without parsing the body
+		if f := parseFault(httpErr.ResponseBody); f != nil {
+			return f.mapped()
+		}
+		return err
+	}
+
+	var fault *soap.SOAPFault
+	if errors.As(err, &fault) {
+		f := &SOAPFault{FaultCode: fault.Code, FaultString: fault.String}
+		if fault.Detail != nil && fault.Detail.HasData() {
+			f.Detail = fault.Detail.ErrorString()
+		}
+		return f.mapped()
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return fmt.Errorf("%w: %w", ErrTimeout, err)
+	}
+
+	var netErr net.Error
+	if errors.As(err, &netErr) {
+		if netErr.Timeout() {
+			return fmt.Errorf("%w: %w", ErrTimeout, err)
+		}
+		return &NetworkError{Op: action, Err: err}
+	}
+
+	return err
+}
+
+// errorType classifies an error for tracing and metrics.
+func errorType(err error) string {
+	var netErr *NetworkError
+	var soapFault *SOAPFault
+	var apiErr *APIError
+	var httpErr *soap.HTTPError
+
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, ErrQuotaExhausted):
+		return "quota_exhausted"
+	case errors.Is(err, ErrRateLimited):
+		return "rate_limited"
+	case errors.Is(err, ErrTimeout):
+		return "timeout"
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, ErrAuthRequired):
+		return "auth_required"
+	case errors.Is(err, ErrTokenExpired):
+		return "token_expired"
+	case errors.As(err, &netErr):
+		return "network"
+	case errors.As(err, &soapFault):
+		return "soap_fault"
+	case errors.As(err, &apiErr):
+		return "api"
+	case errors.As(err, &httpErr):
+		return "http"
+	default:
+		return "other"
+	}
+}
+
 // IsRetryable returns true if the error is potentially retryable.
 func IsRetryable(err error) bool {
 	if err == nil {
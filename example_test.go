@@ -2,11 +2,15 @@ package tradera_test
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
+	"net/url"
 	"time"
 
 	tradera "github.com/SebbeJohansson/tradera-go-client"
+	"github.com/SebbeJohansson/tradera-go-client/middleware"
 )
 
 // This example shows how to create a basic client and search for items.
@@ -66,15 +70,20 @@ func Example_getCategories() {
 	ctx := context.Background()
 
 	// Get all categories
-	categories, err := client.Public().GetCategories(ctx)
+	tree, err := client.Public().GetCategories(ctx)
 	if err != nil {
 		log.Fatal(err)
 	}
 
 	fmt.Println("Categories:")
-	for _, cat := range categories {
+	for _, cat := range tree.Roots {
 		fmt.Printf("- %s (ID: %d)\n", cat.Name, cat.ID)
 	}
+
+	// Find categories by name and show where they are
+	for _, cat := range tree.Search("kameror") {
+		fmt.Printf("%s (ID: %d, leaf: %t)\n", tree.Breadcrumb(cat.ID), cat.ID, cat.IsLeaf())
+	}
 }
 
 // This example shows how to configure the client with rate limiting and retries.
@@ -204,7 +213,7 @@ func Example_advancedSearch() {
 		CategoryID:   345262, // Electronics > Phones
 		PriceMinimum: &minPrice,
 		PriceMaximum: &maxPrice,
-		OrderBy:      "EndDateAscending",
+		OrderBy:      tradera.SearchOrderByEndDateAscending,
 	}
 
 	result, err := client.Search().SearchAdvanced(ctx, params)
@@ -214,3 +223,509 @@ func Example_advancedSearch() {
 
 	fmt.Printf("Found %d iPhones between 1000-5000 SEK\n", result.TotalNumberOfItems)
 }
+
+// This example shows how to use a bounded LRU cache instead of the default
+// unbounded in-memory cache.
+func Example_boundedCache() {
+	cache := middleware.NewLRUCache(middleware.LRUConfig{
+		DefaultTTL: time.Hour,
+		MaxEntries: 1000,
+		MaxBytes:   64 << 20, // 64 MiB
+	})
+
+	config := tradera.DefaultConfig(12345, "your-app-key").WithCacheStore(cache)
+
+	client, err := tradera.NewClient(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+	if _, err := client.Public().GetCategories(ctx); err != nil {
+		log.Fatal(err)
+	}
+
+	stats := client.CacheStats()
+	fmt.Printf("Hits: %d, misses: %d, evictions: %d\n", stats.Hits, stats.Misses, stats.Evictions)
+}
+
+// This example shows how to enforce a daily call quota, keeping part of the
+// budget for high-priority requests.
+func Example_quota() {
+	config := tradera.DefaultConfig(12345, "your-app-key").
+		WithQuota(middleware.QuotaAllServices, 10000, 500)
+	config.QuotaStore = middleware.NewFileQuotaStore("tradera-quota.json")
+
+	client, err := tradera.NewClient(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	// Interactive requests may use the reserved budget
+	ctx := middleware.WithPriority(context.Background(), middleware.PriorityHigh)
+
+	_, err = client.Search().Search(ctx, "vintage camera", 0)
+	var quotaErr *tradera.QuotaError
+	if errors.As(err, &quotaErr) {
+		fmt.Printf("Quota exhausted, resets in %s\n", quotaErr.ResetIn)
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	usage := client.QuotaUsage(middleware.QuotaAllServices)
+	fmt.Printf("Used %d of %d calls\n", usage.Used, usage.Limit)
+}
+
+// This example shows how to add a custom interceptor and a transport hook.
+func Example_interceptors() {
+	logCalls := func(ctx context.Context, call *tradera.Call, next tradera.Invoker) error {
+		start := time.Now()
+		err := next(ctx, call)
+		fmt.Printf("%s/%s attempt %d took %s (err: %v)\n", call.Service, call.Action, call.Attempt, time.Since(start), err)
+		return err
+	}
+
+	userAgent := func(next http.RoundTripper) http.RoundTripper {
+		return tradera.RoundTripperFunc(func(req *http.Request) (*http.Response, error) {
+			req.Header.Set("User-Agent", "my-app/1.0")
+			return next.RoundTrip(req)
+		})
+	}
+
+	config := tradera.DefaultConfig(12345, "your-app-key").
+		WithRetry(3, time.Second).
+		WithInterceptors(logCalls).
+		WithTransportHook(userAgent)
+
+	client, err := tradera.NewClient(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = client.Search().Search(context.Background(), "vintage camera", 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// This example shows how to override client settings for a single call.
+func Example_callOptions() {
+	config := tradera.DefaultConfig(12345, "your-app-key").
+		WithCache(5*time.Minute).
+		WithRetry(3, time.Second)
+
+	client, err := tradera.NewClient(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer client.Close()
+
+	ctx := context.Background()
+
+	// Fetch a fresh copy of the item, bypassing the cache
+	item, err := client.Public().GetItem(ctx, 123456789, tradera.WithNoCache())
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Item: %s\n", item.ShortDescription)
+
+	// Give a slow call more time and act on behalf of a specific seller
+	orders, err := client.Order().GetSellerOrders(ctx,
+		tradera.WithTimeout(2*time.Minute),
+		tradera.WithUser(98765, "seller-token"),
+	)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("Found %d orders\n", len(orders))
+
+	// Options on the context apply to every call made with it
+	background := tradera.WithCallOptions(ctx, tradera.WithPriority(middleware.PriorityLow), tradera.WithRetry(0))
+	_, err = client.Search().Search(background, "vintage camera", 0)
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// This example shows one client acting for many sellers.
+func Example_multiTenant() {
+	// In a real application the credentials come from a database
+	sellers := map[string]tradera.Credentials{
+		"shop-a": {UserID: 1001, Token: "token-a"},
+		"shop-b": {UserID: 1002, Token: "token-b"},
+	}
+
+	provider := tradera.CredentialProviderFunc(func(ctx context.Context) (tradera.Credentials, error) {
+		tenant, ok := tradera.TenantFromContext(ctx)
+		if !ok {
+			return tradera.Credentials{}, errors.New("no tenant in context")
+		}
+		return sellers[tenant], nil
+	})
+
+	client, err := tradera.NewClient(tradera.DefaultConfig(12345, "your-app-key").
+		WithRateLimit(5).
+		WithCredentialProvider(provider))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	for tenant := range sellers {
+		ctx := tradera.WithTenant(context.Background(), tenant)
+		orders, err := client.Order().GetSellerOrders(ctx)
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%s: %d orders\n", tenant, len(orders))
+	}
+}
+
+// This example shows a web application obtaining user tokens through
+// Tradera's token login.
+func Example_tokenLogin() {
+	client, err := tradera.NewClient(tradera.DefaultConfig(12345, "your-app-key"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	login := tradera.NewTokenLogin(client, "your-public-key",
+		func(w http.ResponseWriter, r *http.Request, result *tradera.TokenResult, err error) {
+			if errors.Is(err, tradera.ErrLoginRejected) {
+				http.Error(w, "You declined access", http.StatusForbidden)
+				return
+			}
+			if err != nil {
+				http.Error(w, "Login failed", http.StatusBadGateway)
+				return
+			}
+			// Store result.UserID, result.Token and result.Expires for later calls
+			fmt.Fprintf(w, "Logged in as user %d\n", result.UserID)
+		})
+
+	// Configure /tradera/callback as both the accept and reject URL
+	http.Handle("/tradera/login", login.StartHandler())
+	http.Handle("/tradera/callback", login.CallbackHandler())
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
+
+// This example shows tokens kept in an encrypted file, with a warning
+// before they expire.
+func Example_credentialStore() {
+	key := []byte("0123456789abcdef0123456789abcdef") // load from a secret manager
+	store, err := tradera.NewFileCredentialStore("tradera-credentials.bin", key)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Save the result of a token login
+	err = store.Put("shop-a", tradera.Credentials{
+		UserID:  1001,
+		Token:   "token-a",
+		Expires: time.Now().AddDate(0, 6, 0),
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	config := tradera.DefaultConfig(12345, "your-app-key").
+		WithCredentialProvider(tradera.NewStoreCredentialProvider(store)).
+		WithTokenExpiryWarning(14*24*time.Hour, func(ctx context.Context, creds tradera.Credentials) {
+			tenant, _ := tradera.TenantFromContext(ctx)
+			fmt.Printf("Token of %s expires %s, ask the seller to log in again\n", tenant, creds.Expires.Format(time.DateOnly))
+		})
+
+	client, err := tradera.NewClient(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := tradera.WithTenant(context.Background(), "shop-a")
+	_, err = client.Restricted().GetUserInfo(ctx)
+	if errors.Is(err, tradera.ErrTokenExpired) {
+		fmt.Println("Token expired, re-authorization required")
+		return
+	}
+	if err != nil {
+		log.Fatal(err)
+	}
+}
+
+// This example shows how to check the configuration at startup.
+func Example_verify() {
+	config := tradera.DefaultConfig(12345, "your-app-key").
+		WithUserAuth(98765, "user-token")
+
+	client, err := tradera.NewClient(config)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	report := client.Verify(ctx)
+	if err := report.Err(); err != nil {
+		log.Fatalf("Tradera configuration check failed: %v", err)
+	}
+	fmt.Printf("App auth: %t, user auth: %t, clock skew: %s\n", report.AppAuth, report.UserAuth, report.ClockSkew)
+}
+
+// This example shows how to range over all pages of a search.
+func Example_searchIterator() {
+	client, err := tradera.NewClient(tradera.DefaultConfig(12345, "your-app-key").WithRateLimit(2))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	ctx := context.Background()
+	req := tradera.SearchRequest{Query: "vintage camera"}
+
+	// Stop after 500 items, however many pages that takes
+	for item, err := range client.Search().All(ctx, req, 500) {
+		if err != nil {
+			log.Fatal(err)
+		}
+		fmt.Printf("%d: %s\n", item.ID, item.ShortDescription)
+	}
+}
+
+// This example shows how to fetch a large result set concurrently.
+func Example_searchParallel() {
+	client, err := tradera.NewClient(tradera.DefaultConfig(12345, "your-app-key").WithRateLimit(5))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	result, err := client.Search().SearchParallel(context.Background(), tradera.SearchRequest{Query: "lego"}, 8)
+	if result == nil {
+		log.Fatal(err)
+	}
+	if err != nil {
+		// Some pages failed; the items of the others are still in result
+		log.Printf("incomplete result: %v", err)
+	}
+	fmt.Printf("Fetched %d of %d items\n", len(result.Items), result.TotalNumberOfItems)
+}
+
+// This example shows how to build an advanced search from configuration
+// strings, rejecting unknown values early.
+func Example_searchEnums() {
+	orderBy, err := tradera.ParseSearchOrderBy("priceascending")
+	if err != nil {
+		log.Fatal(err)
+	}
+	itemType, err := tradera.ParseSearchItemType("Auction")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	fmt.Println(orderBy, itemType)
+
+	_, err = tradera.ParseSearchSellerType("Business")
+	fmt.Println(errors.Is(err, tradera.ErrInvalidParameter))
+	// Output:
+	// PriceAscending Auction
+	// true
+}
+
+// This example shows how to build a search and bookmark it as a URL.
+func Example_query() {
+	q := tradera.NewQuery().
+		Words("hasselblad").
+		InCategory(16).
+		Price(1000, 20000).
+		Condition(tradera.SearchItemConditionOnlySecondHand).
+		OrderBy(tradera.SearchOrderByEndDateAscending)
+
+	link := "https://example.com/search?" + q.Encode()
+	fmt.Println(link)
+
+	// Restore the same search from the bookmarked link
+	u, _ := url.Parse(link)
+	restored, err := tradera.QueryFromValues(u.Query())
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Println(restored.Encode() == q.Encode())
+
+	_, err = tradera.ParseQuery("price_min=500&price_max=100")
+	fmt.Println(errors.Is(err, tradera.ErrInvalidParameter))
+	// Output:
+	// https://example.com/search?category=16&condition=OnlySecondHand&price_max=20000&price_min=1000&q=hasselblad&sort=EndDateAscending
+	// true
+	// true
+}
+
+// This example shows how to describe a search once and use it for both the
+// result list and the category counts.
+func ExampleQuery_AdvancedRequest() {
+	client, err := tradera.NewClient(tradera.DefaultConfig(12345, "your-app-key"))
+	if err != nil {
+		log.Fatal(err)
+	}
+	ctx := context.Background()
+
+	q := tradera.NewQuery().
+		Words("hasselblad").
+		InCategory(16).
+		Price(1000, 20000)
+
+	req, err := q.AdvancedRequest()
+	if err != nil {
+		log.Fatal(err)
+	}
+	result, err := client.Search().SearchAdvanced(ctx, req)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	countReq, err := q.CategoryCountRequest()
+	if err != nil {
+		log.Fatal(err)
+	}
+	counts, err := client.Search().SearchCategoryCount(ctx, countReq)
+	if err != nil {
+		log.Fatal(err)
+	}
+	fmt.Printf("%d items in %d categories\n", result.TotalNumberOfItems, len(counts.Categories))
+}
+
+// This example shows how to get alerts for new and cheaper items matching
+// saved searches.
+func Example_savedSearch() {
+	client, err := tradera.NewClient(tradera.DefaultConfig(12345, "your-app-key").WithRateLimit(1))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store := tradera.NewFileSavedSearchStore("saved-searches.json")
+	err = store.Put(tradera.SavedSearch{
+		Name:     "rolleiflex",
+		Query:    tradera.NewQuery().Words("rolleiflex").MaxPrice(4000),
+		Interval: 10 * time.Minute,
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	matches := make(chan tradera.Match)
+	watcher := tradera.NewSearchWatcher(client, store, tradera.MatchChannel(matches))
+
+	go func() {
+		for m := range matches {
+			fmt.Printf("%s: %s %s %s\n", m.Search, m.Kind, m.Item.ShortDescription, m.Item.ItemURL)
+		}
+	}()
+
+	// Runs until the context is cancelled
+	if err := watcher.Run(context.Background()); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// This example shows how to serve saved searches and ad-hoc queries as
+// feeds, e.g. /feeds/atom/rolleiflex or /feeds/rss?q=leica&price_max=5000.
+func Example_feeds() {
+	// Cache results so feed readers polling often share API calls
+	client, err := tradera.NewClient(tradera.DefaultConfig(12345, "your-app-key").WithCache(10 * time.Minute))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	store := tradera.NewFileSavedSearchStore("saved-searches.json")
+	http.Handle("/feeds/", http.StripPrefix("/feeds", tradera.NewFeedHandler(client, store)))
+	log.Fatal(http.ListenAndServe(":8080", nil))
+}
+
+// This example shows how to filter and rank search results client side,
+// paging until enough items pass the filters.
+func Example_filters() {
+	client, err := tradera.NewClient(tradera.DefaultConfig(12345, "your-app-key"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	req := tradera.SearchAdvancedRequest{SearchWords: "tennisbollar"}
+	filter := tradera.AllOf(
+		tradera.ExcludeWords("begagnade", "trasig"),
+		tradera.ExcludeSellers("spammer123"),
+		tradera.MinSellerDSR(4.5),
+		tradera.EndsWithin(48*time.Hour),
+		tradera.HasImages(),
+	)
+
+	items, err := client.Search().CollectMatching(context.Background(), req, 20, filter)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	tradera.SortItems(items, tradera.ByPricePerUnit(nil), tradera.ByEndDate)
+	for _, item := range items {
+		fmt.Printf("%s (%.1f units) %s\n", item.ShortDescription, tradera.UnitsFromTitle(item), item.ItemURL)
+	}
+}
+
+// This example shows how to search several synonyms at once and merge the
+// results.
+func Example_federatedSearch() {
+	client, err := tradera.NewClient(tradera.DefaultConfig(12345, "your-app-key"))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	words := []string{"cykel", "velociped", "racer"}
+	reqs := make([]tradera.SearchAdvancedRequest, len(words))
+	for i, w := range words {
+		reqs[i] = tradera.SearchAdvancedRequest{SearchWords: w, ItemsPerPage: 100}
+	}
+
+	result, err := client.Search().SearchFederated(context.Background(), reqs, 0, tradera.ByEndDate)
+	if result == nil {
+		log.Fatal(err)
+	}
+	if err != nil {
+		// Some queries failed; the items of the others are still in result
+		log.Printf("incomplete result: %v", err)
+	}
+
+	for _, item := range result.Items {
+		var matched []string
+		for _, q := range item.Queries {
+			matched = append(matched, words[q])
+		}
+		fmt.Printf("%s (matched %v)\n", item.ShortDescription, matched)
+	}
+}
+
+// This example shows how to show item counts per category for a search,
+// e.g. in a sidebar next to its results.
+func Example_categoryFacets() {
+	client, err := tradera.NewClient(tradera.DefaultConfig(12345, "your-app-key").WithCache(time.Hour))
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	req, err := tradera.NewQuery().Words("polaroid").CategoryCountRequest()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	// Leave out categories without matching items
+	facets, err := client.Search().CategoryFacets(context.Background(), req, true)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	var show func(cats []*tradera.CountedCategory, indent string)
+	show = func(cats []*tradera.CountedCategory, indent string) {
+		for _, cat := range cats {
+			fmt.Printf("%s%s (%d)\n", indent, cat.Name, cat.NoOfItemsInCategoryIncludingChildren)
+			show(cat.Children, indent+"  ")
+		}
+	}
+	show(facets, "")
+}
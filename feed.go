@@ -0,0 +1,394 @@
+package tradera
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"html"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultFeedItems is how many items a feed shows by default.
+	defaultFeedItems = 50
+
+	// feedForgetAfter is how long a FeedHandler remembers an item that no
+	// feed has shown, bounding its memory across ad-hoc queries.
+	feedForgetAfter = 24 * time.Hour
+)
+
+// Feed formats, as used in FeedHandler paths.
+const (
+	FeedAtom = "atom"
+	FeedRSS  = "rss"
+)
+
+// FeedHandler serves searches as Atom 1.0 and RSS 2.0 feeds, for feed
+// readers. It answers
+//
+//	GET /atom?<query>      an ad-hoc Query encoded with Query.Encode
+//	GET /rss?<query>
+//	GET /atom/{name}       a saved search from the store
+//	GET /rss/{name}
+//
+// Mount it under a prefix with http.StripPrefix.
+//
+// Results are cached through the client cache under the encoded query, so
+// readers polling the same feed share one API call per cache period. Enable
+// caching with Config.WithCache or Config.WithCacheStore.
+//
+// Entries are dated by when their item first matched and when its price or
+// bids last changed, as recorded by a SearchWatcher for saved searches, or
+// else by the handler itself while it runs.
+type FeedHandler struct {
+	client *Client
+	store  SavedSearchStore
+	mux    *http.ServeMux
+
+	mu   sync.Mutex
+	seen map[int32]feedItem // items of feeds not tracked by a watcher, by ID
+
+	// MaxItems is how many items a feed shows unless its query sets
+	// PerPage (default: 50).
+	MaxItems int
+}
+
+// NewFeedHandler creates a feed handler. store holds the saved searches
+// served by name; it may be nil to serve ad-hoc queries only.
+func NewFeedHandler(client *Client, store SavedSearchStore) *FeedHandler {
+	h := &FeedHandler{
+		client:   client,
+		store:    store,
+		mux:      http.NewServeMux(),
+		seen:     make(map[int32]feedItem),
+		MaxItems: defaultFeedItems,
+	}
+	h.mux.HandleFunc("GET /{format}", h.serveQuery)
+	h.mux.HandleFunc("GET /{format}/{name}", h.serveSaved)
+	return h
+}
+
+// ServeHTTP implements http.Handler.
+func (h *FeedHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	h.mux.ServeHTTP(w, r)
+}
+
+// serveQuery serves the feed of an ad-hoc query.
+func (h *FeedHandler) serveQuery(w http.ResponseWriter, r *http.Request) {
+	q, err := QueryFromValues(r.URL.Query())
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	title := "Tradera search"
+	if q.words != "" {
+		title = "Tradera: " + q.words
+	}
+	h.serve(w, r, title, q, nil)
+}
+
+// serveSaved serves the feed of a saved search.
+func (h *FeedHandler) serveSaved(w http.ResponseWriter, r *http.Request) {
+	if h.store == nil {
+		http.NotFound(w, r)
+		return
+	}
+	name := r.PathValue("name")
+	search, ok, err := h.store.Get(name)
+	if err != nil {
+		http.Error(w, "failed to load saved search", http.StatusInternalServerError)
+		return
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	h.serve(w, r, "Tradera: "+search.Name, search.Query, search.Seen)
+}
+
+// serve runs q and writes its items in the requested format. tracked holds
+// the items a watcher has recorded for q, if any.
+func (h *FeedHandler) serve(w http.ResponseWriter, r *http.Request, title string, q Query, tracked map[int32]SeenItem) {
+	format := r.PathValue("format")
+	if format != FeedAtom && format != FeedRSS {
+		http.NotFound(w, r)
+		return
+	}
+
+	result, err := h.search(r.Context(), q)
+	if err != nil {
+		status := http.StatusBadGateway
+		switch {
+		case errors.Is(err, ErrInvalidParameter):
+			status = http.StatusBadRequest
+		case errors.Is(err, ErrRateLimited), errors.Is(err, ErrQuotaExhausted):
+			status = http.StatusServiceUnavailable
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	self := requestURL(r)
+	now := time.Now()
+	seen := h.observe(result.Items, tracked, now)
+
+	var feed any
+	contentType := "application/atom+xml; charset=utf-8"
+	if format == FeedAtom {
+		feed = newAtomFeed(title, self, now, result.Items, seen)
+	} else {
+		contentType = "application/rss+xml; charset=utf-8"
+		feed = newRSSFeed(title, self, now, result.Items, seen)
+	}
+
+	// Render fully before writing, so an encoding failure can still be reported
+	var buf bytes.Buffer
+	buf.WriteString(xml.Header)
+	enc := xml.NewEncoder(&buf)
+	enc.Indent("", "  ")
+	if err := enc.Encode(feed); err != nil {
+		h.client.logger.ErrorContext(r.Context(), "tradera: rendering feed failed", "feed", title, "error", err)
+		http.Error(w, "failed to render feed", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		h.client.logger.DebugContext(r.Context(), "tradera: writing feed failed", "feed", title, "error", err)
+	}
+}
+
+// feedItem is an item a FeedHandler remembers, with when a feed last showed it.
+type feedItem struct {
+	SeenItem
+	lastShown time.Time
+}
+
+// observe returns when each item was first seen and last changed: from
+// tracked if a watcher recorded it, else from what this handler has seen.
+// Items the handler remembers are forgotten once they end or no feed has
+// shown them for feedForgetAfter.
+func (h *FeedHandler) observe(items []*SearchItem, tracked map[int32]SeenItem, now time.Time) map[int32]SeenItem {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	for id, item := range h.seen {
+		ended := !item.EndDate.IsZero() && item.EndDate.Before(now)
+		if ended || now.Sub(item.lastShown) > feedForgetAfter {
+			delete(h.seen, id)
+		}
+	}
+
+	seen := make(map[int32]SeenItem, len(items))
+	for _, item := range items {
+		if t, ok := tracked[item.ID]; ok && !t.FirstSeen.IsZero() {
+			seen[item.ID] = t
+			continue
+		}
+		previous, ok := h.seen[item.ID]
+		current := observeItem(item, previous.SeenItem, ok, now)
+		h.seen[item.ID] = feedItem{SeenItem: current, lastShown: now}
+		seen[item.ID] = current
+	}
+	return seen
+}
+
+// search runs q, cached under its encoding.
+func (h *FeedHandler) search(ctx context.Context, q Query) (*SearchResult, error) {
+	if q.perPage == 0 {
+		maxItems := h.MaxItems
+		if maxItems <= 0 {
+			maxItems = defaultFeedItems
+		}
+		q = q.PerPage(int32(maxItems))
+	}
+	req, err := q.AdvancedRequest()
+	if err != nil {
+		return nil, err
+	}
+	return h.client.Search().searchAdvanced(ctx, req, "search:"+q.Encode(), nil)
+}
+
+// requestURL returns the absolute URL of r, as the feed's own link.
+func requestURL(r *http.Request) string {
+	scheme := "http"
+	if r.TLS != nil {
+		scheme = "https"
+	}
+	uri := r.RequestURI
+	if uri == "" {
+		uri = r.URL.RequestURI()
+	}
+	return scheme + "://" + r.Host + uri
+}
+
+// itemID returns a permanent identifier for item.
+func itemID(item *SearchItem) string {
+	if item.ItemURL != "" {
+		return item.ItemURL
+	}
+	return fmt.Sprintf("urn:tradera:item:%d", item.ID)
+}
+
+// itemSummary describes item in HTML: thumbnail, price, bids, end date and seller.
+func itemSummary(item *SearchItem) string {
+	var lines []string
+	switch {
+	case item.HasBids && item.MaxBid != nil:
+		lines = append(lines, fmt.Sprintf("Leading bid: %d kr (%d bids)", *item.MaxBid, item.BidCount))
+	case item.NextBid != nil && *item.NextBid > 0:
+		lines = append(lines, fmt.Sprintf("Starting bid: %d kr", *item.NextBid))
+	}
+	if item.BuyItNowPrice != nil && *item.BuyItNowPrice > 0 {
+		lines = append(lines, fmt.Sprintf("Buy now: %d kr", *item.BuyItNowPrice))
+	}
+	if end := itemEnd(item); !end.IsZero() {
+		lines = append(lines, "Ends: "+end.Format("2006-01-02 15:04"))
+	}
+	if item.SellerAlias != "" {
+		lines = append(lines, "Seller: "+html.EscapeString(item.SellerAlias))
+	}
+
+	var b strings.Builder
+	if item.ThumbnailLink != "" {
+		fmt.Fprintf(&b, `<p><img src="%s" alt=""></p>`, html.EscapeString(item.ThumbnailLink))
+	}
+	fmt.Fprintf(&b, "<p>%s</p>", strings.Join(lines, "<br>"))
+	return b.String()
+}
+
+// Atom 1.0 document, RFC 4287.
+type atomFeed struct {
+	XMLName xml.Name    `xml:"http://www.w3.org/2005/Atom feed"`
+	Title   string      `xml:"title"`
+	ID      string      `xml:"id"`
+	Updated string      `xml:"updated"`
+	Author  atomAuthor  `xml:"author"`
+	Links   []atomLink  `xml:"link"`
+	Entries []atomEntry `xml:"entry"`
+}
+
+type atomAuthor struct {
+	Name string `xml:"name"`
+}
+
+type atomLink struct {
+	Rel  string `xml:"rel,attr,omitempty"`
+	Href string `xml:"href,attr"`
+}
+
+type atomEntry struct {
+	Title     string      `xml:"title"`
+	ID        string      `xml:"id"`
+	Published string      `xml:"published"`
+	Updated   string      `xml:"updated"`
+	Author    *atomAuthor `xml:"author,omitempty"`
+	Links     []atomLink  `xml:"link"`
+	Content   atomContent `xml:"content"`
+}
+
+type atomContent struct {
+	Type string `xml:"type,attr"`
+	Body string `xml:",chardata"`
+}
+
+func newAtomFeed(title, self string, now time.Time, items []*SearchItem, seen map[int32]SeenItem) *atomFeed {
+	feed := &atomFeed{
+		Title:   title,
+		ID:      self,
+		Updated: lastChange(items, seen, now).UTC().Format(time.RFC3339),
+		Author:  atomAuthor{Name: "Tradera"},
+		Links:   []atomLink{{Rel: "self", Href: self}},
+	}
+	for _, item := range items {
+		entry := atomEntry{
+			Title:     item.ShortDescription,
+			ID:        itemID(item),
+			Published: seen[item.ID].FirstSeen.UTC().Format(time.RFC3339),
+			Updated:   seen[item.ID].Changed.UTC().Format(time.RFC3339),
+			Content:   atomContent{Type: "html", Body: itemSummary(item)},
+		}
+		if item.ItemURL != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "alternate", Href: item.ItemURL})
+		}
+		if item.ThumbnailLink != "" {
+			entry.Links = append(entry.Links, atomLink{Rel: "enclosure", Href: item.ThumbnailLink})
+		}
+		if item.SellerAlias != "" {
+			entry.Author = &atomAuthor{Name: item.SellerAlias}
+		}
+		feed.Entries = append(feed.Entries, entry)
+	}
+	return feed
+}
+
+// RSS 2.0 document.
+type rssFeed struct {
+	XMLName xml.Name   `xml:"rss"`
+	Version string     `xml:"version,attr"`
+	Channel rssChannel `xml:"channel"`
+}
+
+type rssChannel struct {
+	Title         string    `xml:"title"`
+	Link          string    `xml:"link"`
+	Description   string    `xml:"description"`
+	LastBuildDate string    `xml:"lastBuildDate"`
+	Items         []rssItem `xml:"item"`
+}
+
+type rssItem struct {
+	Title       string  `xml:"title"`
+	Link        string  `xml:"link,omitempty"`
+	Description string  `xml:"description"`
+	GUID        rssGUID `xml:"guid"`
+	PubDate     string  `xml:"pubDate"`
+}
+
+type rssGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+func newRSSFeed(title, self string, now time.Time, items []*SearchItem, seen map[int32]SeenItem) *rssFeed {
+	feed := &rssFeed{
+		Version: "2.0",
+		Channel: rssChannel{
+			Title:         title,
+			Link:          self,
+			Description:   title,
+			LastBuildDate: lastChange(items, seen, now).UTC().Format(time.RFC1123Z),
+		},
+	}
+	for _, item := range items {
+		feed.Channel.Items = append(feed.Channel.Items, rssItem{
+			Title:       item.ShortDescription,
+			Link:        item.ItemURL,
+			Description: itemSummary(item),
+			GUID:        rssGUID{IsPermaLink: item.ItemURL != "", Value: itemID(item)},
+			PubDate:     seen[item.ID].FirstSeen.UTC().Format(time.RFC1123Z),
+		})
+	}
+	return feed
+}
+
+// lastChange returns when the latest of items last changed, or now for an
+// empty feed.
+func lastChange(items []*SearchItem, seen map[int32]SeenItem, now time.Time) time.Time {
+	var last time.Time
+	for _, item := range items {
+		if changed := seen[item.ID].Changed; changed.After(last) {
+			last = changed
+		}
+	}
+	if last.IsZero() {
+		return now
+	}
+	return last
+}
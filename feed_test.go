@@ -0,0 +1,136 @@
+package tradera_test
+
+import (
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	tradera "github.com/SebbeJohansson/tradera-go-client"
+)
+
+// atomDoc is the part of an Atom feed the tests read.
+type atomDoc struct {
+	Title   string `xml:"title"`
+	Entries []struct {
+		ID        string `xml:"id"`
+		Published string `xml:"published"`
+		Updated   string `xml:"updated"`
+	} `xml:"entry"`
+}
+
+// rssDoc is the part of an RSS feed the tests read.
+type rssDoc struct {
+	Channel struct {
+		Title string `xml:"title"`
+		Items []struct {
+			GUID    string `xml:"guid"`
+			PubDate string `xml:"pubDate"`
+		} `xml:"item"`
+	} `xml:"channel"`
+}
+
+// getFeed fetches path from the feed server and decodes it into doc.
+func getFeed(t *testing.T, feeds *httptest.Server, path, contentType string, doc any) {
+	t.Helper()
+
+	resp, err := http.Get(feeds.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("GET %s: status %d: %s", path, resp.StatusCode, body)
+	}
+	if got := resp.Header.Get("Content-Type"); got != contentType {
+		t.Fatalf("GET %s: content type %q, want %q", path, got, contentType)
+	}
+	if err := xml.Unmarshal(body, doc); err != nil {
+		t.Fatalf("GET %s: parsing feed: %v\n%s", path, err, body)
+	}
+}
+
+func TestFeedHandlerDatesStable(t *testing.T) {
+	server := newFakeTradera(t, http.StatusOK, searchResponse(
+		searchItem(1, 100, 0, endsLater),
+		searchItem(2, 200, 0, endsLater),
+	))
+	feeds := httptest.NewServer(tradera.NewFeedHandler(newTestClient(t, server), nil))
+	t.Cleanup(feeds.Close)
+
+	var atom1, atom2 atomDoc
+	var rss1, rss2 rssDoc
+	getFeed(t, feeds, "/atom?q=leica", "application/atom+xml; charset=utf-8", &atom1)
+	getFeed(t, feeds, "/rss?q=leica", "application/rss+xml; charset=utf-8", &rss1)
+	if atom1.Title != "Tradera: leica" || len(atom1.Entries) != 2 || len(rss1.Channel.Items) != 2 {
+		t.Fatalf("first poll: atom %+v, rss %+v, want 2 entries titled for the query", atom1, rss1)
+	}
+
+	// Poll again a second later, after item 1 received a bid
+	time.Sleep(1100 * time.Millisecond)
+	server.respond(searchResponse(
+		searchItem(1, 110, 1, endsLater),
+		searchItem(2, 200, 0, endsLater),
+	))
+	getFeed(t, feeds, "/atom?q=leica", "application/atom+xml; charset=utf-8", &atom2)
+	getFeed(t, feeds, "/rss?q=leica", "application/rss+xml; charset=utf-8", &rss2)
+
+	for i := range 2 {
+		if atom2.Entries[i].Published != atom1.Entries[i].Published {
+			t.Fatalf("entry %s published %s, then %s", atom1.Entries[i].ID, atom1.Entries[i].Published, atom2.Entries[i].Published)
+		}
+		if rss2.Channel.Items[i].PubDate != rss1.Channel.Items[i].PubDate {
+			t.Fatalf("item %s pubDate %s, then %s", rss1.Channel.Items[i].GUID, rss1.Channel.Items[i].PubDate, rss2.Channel.Items[i].PubDate)
+		}
+	}
+	if atom2.Entries[0].Updated == atom1.Entries[0].Updated {
+		t.Fatalf("entry with a new bid kept updated %s", atom1.Entries[0].Updated)
+	}
+	if atom2.Entries[1].Updated != atom1.Entries[1].Updated {
+		t.Fatalf("unchanged entry updated %s, then %s", atom1.Entries[1].Updated, atom2.Entries[1].Updated)
+	}
+}
+
+func TestFeedHandlerSavedSearch(t *testing.T) {
+	server := newFakeTradera(t, http.StatusOK, searchResponse(searchItem(1, 100, 0, endsLater)))
+
+	firstSeen := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	store := tradera.NewMemorySavedSearchStore()
+	store.Put(tradera.SavedSearch{
+		Name:  "leica",
+		Query: tradera.NewQuery().Words("leica"),
+		Seen:  map[int32]tradera.SeenItem{1: {Price: 100, FirstSeen: firstSeen, Changed: firstSeen}},
+	})
+	feeds := httptest.NewServer(tradera.NewFeedHandler(newTestClient(t, server), store))
+	t.Cleanup(feeds.Close)
+
+	// Entries are dated by what the watcher recorded
+	var atom atomDoc
+	getFeed(t, feeds, "/atom/leica", "application/atom+xml; charset=utf-8", &atom)
+	if len(atom.Entries) != 1 || atom.Entries[0].Published != "2026-05-04T12:00:00Z" {
+		t.Fatalf("saved search feed = %+v, want one entry published when first seen", atom)
+	}
+	var rss rssDoc
+	getFeed(t, feeds, "/rss/leica", "application/rss+xml; charset=utf-8", &rss)
+	if len(rss.Channel.Items) != 1 || rss.Channel.Items[0].PubDate != firstSeen.Format(time.RFC1123Z) {
+		t.Fatalf("saved search feed = %+v, want one item published when first seen", rss)
+	}
+
+	for _, path := range []string{"/atom/contax", "/json?q=leica"} {
+		resp, err := http.Get(feeds.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Fatalf("GET %s: status %d, want 404", path, resp.StatusCode)
+		}
+	}
+}
@@ -0,0 +1,213 @@
+package tradera
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/SebbeJohansson/tradera-go-client/middleware"
+)
+
+// Call is a single Tradera API operation as it passes through the
+// interceptor chain.
+type Call struct {
+	Service string // one of the Service* constants
+	Action  string // SOAP action name, e.g. "GetItem"
+
+	// Request is the typed SOAP request, e.g. *search.SearchAdvanced.
+	// Interceptors may change the fields it points to before calling next,
+	// but replacing the field itself has no effect: the request sent is the
+	// one the client method built.
+	Request any
+
+	// Response is the typed result, set once the call succeeded or was served
+	// from the cache. Interceptors may inspect or replace it after next returns.
+	Response any
+
+	// Attempt is the current attempt number, starting at 1.
+	Attempt int
+
+	// IDs of the entities the call is about, for tracing (0 = not applicable)
+	ItemID  int32
+	UserID  int32
+	OrderID int32
+
+	// CacheKey enables caching of the call's result under this key
+	CacheKey string
+
+	client   *Client
+	stats    *callStats
+	do       func(context.Context) (any, error) // performs the SOAP request
+	opts     []CallOption                       // passed to the client method
+	options  callOptions                        // resolved from opts and the context
+	userAuth bool                               // the service requires user authorization
+}
+
+// withItem returns a copy of call about the given item.
+func (call Call) withItem(itemID int32) Call {
+	call.ItemID = itemID
+	return call
+}
+
+// withUser returns a copy of call about the given user.
+func (call Call) withUser(userID int32) Call {
+	call.UserID = userID
+	return call
+}
+
+// withOrder returns a copy of call about the given order.
+func (call Call) withOrder(orderID int32) Call {
+	call.OrderID = orderID
+	return call
+}
+
+// withCache returns a copy of call whose result is cached under key.
+func (call Call) withCache(key string) Call {
+	call.CacheKey = key
+	return call
+}
+
+// Invoker runs the rest of the interceptor chain for a call.
+type Invoker func(ctx context.Context, call *Call) error
+
+// Interceptor wraps every API call. It may inspect or modify the call,
+// short-circuit it by returning without calling next, or inspect the
+// response and error returned by next.
+type Interceptor func(ctx context.Context, call *Call, next Invoker) error
+
+// TransportHook wraps the HTTP transport used for SOAP requests. Hooks see
+// the raw envelopes and may rewrite headers or bodies.
+type TransportHook func(next http.RoundTripper) http.RoundTripper
+
+// RoundTripperFunc adapts a function to http.RoundTripper.
+type RoundTripperFunc func(*http.Request) (*http.Response, error)
+
+// RoundTrip implements http.RoundTripper.
+func (f RoundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+// DefaultInterceptors returns the built-in interceptors in their default
+// order: cache, rate limit, retry, quota. Use it to place custom interceptors
+// around or between them in Config.Interceptors.
+func DefaultInterceptors() []Interceptor {
+	return []Interceptor{
+		CacheInterceptor(),
+		RateLimitInterceptor(),
+		RetryInterceptor(),
+		QuotaInterceptor(),
+	}
+}
+
+// CacheInterceptor serves calls that have a cache key from the client's cache
+// and lets concurrent identical calls share one request.
+func CacheInterceptor() Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) error {
+		if call.CacheKey == "" {
+			return next(ctx, call)
+		}
+
+		if call.options.noCache {
+			call.client.loader.Invalidate(call.CacheKey)
+		}
+
+		value, hit, err := call.client.loader.Load(ctx, call.CacheKey, func(ctx context.Context) (interface{}, error) {
+			// Fetch on a copy: a background refresh may outlive this call
+			fetch := *call
+			err := next(ctx, &fetch)
+			return fetch.Response, err
+		})
+		call.stats.cacheLookup.Store(true)
+		call.stats.cacheHit.Store(hit)
+		if err != nil {
+			return err
+		}
+
+		call.Response = value
+		return nil
+	}
+}
+
+// RateLimitInterceptor waits for the client's rate limiter before the call.
+func RateLimitInterceptor() Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) error {
+		limiter := call.client.limiter
+		if limiter == nil {
+			return next(ctx, call)
+		}
+
+		start := time.Now()
+		err := limiter.Wait(ctx)
+		call.stats.rateLimitWait.Add(int64(time.Since(start)))
+		if err != nil {
+			return err
+		}
+
+		return next(ctx, call)
+	}
+}
+
+// RetryInterceptor retries the rest of the chain with the client's retry policy.
+func RetryInterceptor() Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) error {
+		retryer := call.client.retryerFor(call.options)
+		if retryer == nil {
+			return next(ctx, call)
+		}
+
+		attempt := 0
+		return retryer.Do(ctx, func() error {
+			attempt++
+			call.Attempt = attempt
+			return next(ctx, call)
+		})
+	}
+}
+
+// QuotaInterceptor counts the call against the client's quotas. Placed
+// inside RetryInterceptor it charges every attempt, since each one counts
+// against Tradera's budget.
+func QuotaInterceptor() Interceptor {
+	return func(ctx context.Context, call *Call, next Invoker) error {
+		if quota := call.client.quota; quota != nil {
+			if err := quota.Acquire(ctx, call.Service); err != nil {
+				return err
+			}
+		}
+		return next(ctx, call)
+	}
+}
+
+// chainInterceptors builds an Invoker that runs interceptors in order around final.
+func chainInterceptors(interceptors []Interceptor, final Invoker) Invoker {
+	invoker := final
+	for i := len(interceptors) - 1; i >= 0; i-- {
+		interceptor, next := interceptors[i], invoker
+		invoker = func(ctx context.Context, call *Call) error {
+			return interceptor(ctx, call, next)
+		}
+	}
+	return invoker
+}
+
+// send performs the SOAP request of a call. It is the innermost Invoker;
+// errors are mapped here so interceptors see ErrRateLimited, *SOAPFault, etc.
+func (c *Client) send(ctx context.Context, call *Call) error {
+	call.stats.attempts.Add(1)
+	response, err := call.do(ctx)
+	err = mapError(call.Action, err)
+
+	if observer, ok := c.limiter.(middleware.ThrottleObserver); ok {
+		if IsThrottled(err) {
+			observer.OnThrottled()
+		} else if err == nil {
+			observer.OnSuccess()
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+	call.Response = response
+	return nil
+}
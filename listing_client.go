@@ -13,13 +13,26 @@ type ListingClient struct {
 }
 
 func newListingClient(c *Client) *ListingClient {
-	soapClient := c.createSOAPClient(ListingServiceURL)
+	soapClient := c.createSOAPClient(ListingServiceURL, c.configAuth())
 	return &ListingClient{
 		client:  c,
 		service: listing.NewListingServiceSoap(soapClient),
 	}
 }
 
+// op returns the operation descriptor for an action of this service.
+func (c *ListingClient) op(action string, request any, opts []CallOption) Call {
+	return Call{Service: ServiceListing, Action: action, Request: request, opts: opts}
+}
+
+// serviceFor returns the service to call, honoring a per-call user from WithUser.
+func (c *ListingClient) serviceFor(ctx context.Context) listing.ListingServiceSoap {
+	if auth := authFromContext(ctx); auth != nil {
+		return listing.NewListingServiceSoap(c.client.createSOAPClient(ListingServiceURL, auth))
+	}
+	return c.service
+}
+
 // ItemRestarts represents information about item restarts.
 type ItemRestarts struct {
 	LastRestartedItemID int32
@@ -35,11 +48,13 @@ type RestartedItem struct {
 }
 
 // GetItemRestarts retrieves item restart information.
-func (c *ListingClient) GetItemRestarts(ctx context.Context, itemID int32) (*ItemRestarts, error) {
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*listing.GetItemRestartsResponse, error) {
-		return c.service.GetItemRestartsContext(ctx, &listing.GetItemRestarts{
-			ItemId: itemID,
-		})
+func (c *ListingClient) GetItemRestarts(ctx context.Context, itemID int32, opts ...CallOption) (*ItemRestarts, error) {
+	request := &listing.GetItemRestarts{
+		ItemId: itemID,
+	}
+
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetItemRestarts", request, opts).withItem(itemID), func(ctx context.Context) (*listing.GetItemRestartsResponse, error) {
+		return c.serviceFor(ctx).GetItemRestartsContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,126 @@
+package tradera
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"time"
+
+	"github.com/SebbeJohansson/tradera-go-client/middleware"
+)
+
+// redacted replaces secret values in logs and printed configs.
+const redacted = "[REDACTED]"
+
+// secretElements matches the content of SOAP elements holding secrets:
+// the app key, user tokens, and the secret key and token of FetchToken.
+var secretElements = regexp.MustCompile(`(<(?:[\w-]+:)?(?:AppKey|Token|SecretKey|secretKey|FetchTokenResult)(?:\s[^>]*)?>)[^<]*(</)`)
+
+// RedactEnvelope returns a copy of a SOAP envelope with the AppKey, Token,
+// SecretKey and FetchToken result values replaced by "[REDACTED]".
+func RedactEnvelope(envelope []byte) []byte {
+	return secretElements.ReplaceAll(envelope, []byte("${1}"+redacted+"${2}"))
+}
+
+// redact hides a secret value, keeping whether it was set visible.
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redacted
+}
+
+// String implements fmt.Stringer with secrets redacted.
+func (c Config) String() string {
+	return fmt.Sprintf("tradera.Config{AppID: %d, AppKey: %q, UserID: %d, Token: %q, RateLimit: %g, RetryEnabled: %t, MaxRetries: %d, RetryBaseDelay: %s, CacheTTL: %s, Timeout: %s}",
+		c.AppID, redact(c.AppKey), c.UserID, redact(c.Token), c.RateLimit,
+		c.RetryEnabled, c.MaxRetries, c.RetryBaseDelay, c.CacheTTL, c.Timeout)
+}
+
+// GoString implements fmt.GoStringer so %#v also redacts secrets.
+func (c Config) GoString() string {
+	return c.String()
+}
+
+// LogValue implements slog.LogValuer with secrets redacted.
+func (c Config) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.Int("app_id", c.AppID),
+		slog.String("app_key", redact(c.AppKey)),
+		slog.Int("user_id", c.UserID),
+		slog.String("token", redact(c.Token)),
+		slog.Float64("rate_limit", c.RateLimit),
+		slog.Bool("retry_enabled", c.RetryEnabled),
+		slog.Duration("cache_ttl", c.CacheTTL),
+		slog.Duration("timeout", c.Timeout),
+	)
+}
+
+// loggingDiskCache is a DiskCache without an OnError handler whose failed
+// writes are logged instead, leaving the caller's DiskCache untouched.
+type loggingDiskCache struct {
+	*middleware.DiskCache
+	logger *slog.Logger
+}
+
+// Set stores a value in the cache with the default TTL.
+func (d *loggingDiskCache) Set(key string, value interface{}) {
+	d.SetWithTTL(key, value, d.DefaultTTL())
+}
+
+// SetWithTTL stores a value in the cache with a custom TTL.
+func (d *loggingDiskCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	if err := d.Put(key, value, ttl); err != nil {
+		d.logger.Warn("tradera: result not cached", "key", key, "error", err)
+	}
+}
+
+// envelopeLogger is an http.RoundTripper that logs redacted SOAP request
+// and response envelopes at debug level.
+type envelopeLogger struct {
+	next   http.RoundTripper
+	logger *slog.Logger
+}
+
+// RoundTrip implements http.RoundTripper.
+func (l *envelopeLogger) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		req.Body = io.NopCloser(bytes.NewReader(body))
+
+		l.logger.DebugContext(ctx, "tradera: SOAP request",
+			slog.String("url", req.URL.String()),
+			slog.String("soap_action", req.Header.Get("SOAPAction")),
+			slog.String("envelope", string(RedactEnvelope(body))),
+		)
+	}
+
+	resp, err := l.next.RoundTrip(req)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := io.ReadAll(resp.Body)
+	resp.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(body))
+
+	l.logger.DebugContext(ctx, "tradera: SOAP response",
+		slog.String("soap_action", req.Header.Get("SOAPAction")),
+		slog.Int("status", resp.StatusCode),
+		slog.String("envelope", string(RedactEnvelope(body))),
+	)
+
+	return resp, nil
+}
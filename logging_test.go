@@ -0,0 +1,147 @@
+package tradera_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"reflect"
+	"strings"
+	"testing"
+
+	tradera "github.com/SebbeJohansson/tradera-go-client"
+)
+
+func TestRedactEnvelope(t *testing.T) {
+	tests := []struct {
+		name     string
+		envelope string
+		want     string
+	}{
+		{
+			name:     "app key",
+			envelope: `<AuthenticationHeader xmlns="http://api.tradera.com"><AppId>1234</AppId><AppKey>app-secret</AppKey></AuthenticationHeader>`,
+			want:     `<AuthenticationHeader xmlns="http://api.tradera.com"><AppId>1234</AppId><AppKey>[REDACTED]</AppKey></AuthenticationHeader>`,
+		},
+		{
+			name:     "user token",
+			envelope: `<AuthorizationHeader><UserId>42</UserId><Token>user-secret</Token></AuthorizationHeader>`,
+			want:     `<AuthorizationHeader><UserId>42</UserId><Token>[REDACTED]</Token></AuthorizationHeader>`,
+		},
+		{
+			name:     "secret key",
+			envelope: `<FetchToken><userId>42</userId><secretKey>login-secret</secretKey></FetchToken><SecretKey>other-secret</SecretKey>`,
+			want:     `<FetchToken><userId>42</userId><secretKey>[REDACTED]</secretKey></FetchToken><SecretKey>[REDACTED]</SecretKey>`,
+		},
+		{
+			name:     "fetched token",
+			envelope: `<FetchTokenResponse><FetchTokenResult>user-secret</FetchTokenResult></FetchTokenResponse>`,
+			want:     `<FetchTokenResponse><FetchTokenResult>[REDACTED]</FetchTokenResult></FetchTokenResponse>`,
+		},
+		{
+			name:     "prefixes and attributes",
+			envelope: `<tns:AppKey xsi:type="xsd:string">app-secret</tns:AppKey>`,
+			want:     `<tns:AppKey xsi:type="xsd:string">[REDACTED]</tns:AppKey>`,
+		},
+		{
+			name:     "no secrets",
+			envelope: `<SearchAdvanced><SearchWords>Token</SearchWords><AppKeyHint>x</AppKeyHint></SearchAdvanced>`,
+			want:     `<SearchAdvanced><SearchWords>Token</SearchWords><AppKeyHint>x</AppKeyHint></SearchAdvanced>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := string(tradera.RedactEnvelope([]byte(tt.envelope))); got != tt.want {
+				t.Fatalf("RedactEnvelope() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// secretConfig returns a config with every secret string field set to a
+// value naming the field, and those values.
+func secretConfig(t *testing.T) (tradera.Config, []string) {
+	t.Helper()
+
+	config := tradera.DefaultConfig(1234, "")
+	v := reflect.ValueOf(&config).Elem()
+
+	var secrets []string
+	for i := range v.NumField() {
+		field := v.Type().Field(i)
+		if field.Type.Kind() != reflect.String {
+			continue
+		}
+		if !strings.Contains(field.Name, "Key") && !strings.Contains(field.Name, "Token") && !strings.Contains(field.Name, "Secret") {
+			continue
+		}
+		secret := "secret-" + strings.ToLower(field.Name)
+		v.Field(i).SetString(secret)
+		secrets = append(secrets, secret)
+	}
+	if len(secrets) == 0 {
+		t.Fatal("Config has no secret fields")
+	}
+	return config, secrets
+}
+
+func TestConfigRedactsSecrets(t *testing.T) {
+	config, secrets := secretConfig(t)
+
+	var logged bytes.Buffer
+	slog.New(slog.NewJSONHandler(&logged, nil)).Info("config", "config", config)
+	var text bytes.Buffer
+	slog.New(slog.NewTextHandler(&text, nil)).Info("config", "config", config)
+
+	outputs := map[string]string{
+		"%v":        fmt.Sprintf("%v", config),
+		"%+v":       fmt.Sprintf("%+v", config),
+		"%#v":       fmt.Sprintf("%#v", config),
+		"%s":        fmt.Sprintf("%s", config),
+		"slog JSON": logged.String(),
+		"slog text": text.String(),
+	}
+	for format, output := range outputs {
+		t.Run(format, func(t *testing.T) {
+			for _, secret := range secrets {
+				if strings.Contains(output, secret) {
+					t.Fatalf("%s leaks %q: %s", format, secret, output)
+				}
+			}
+			if !strings.Contains(output, "[REDACTED]") {
+				t.Fatalf("%s does not mark redacted secrets: %s", format, output)
+			}
+		})
+	}
+}
+
+func TestEnvelopeLoggingRedactsSecrets(t *testing.T) {
+	server := newFakeTradera(t, http.StatusOK, fetchTokenResponse)
+
+	var logged bytes.Buffer
+	config := tradera.DefaultConfig(1234, "app-secret")
+	config.BaseURL = server.URL
+	config.Logger = slog.New(slog.NewTextHandler(&logged, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	config.LogEnvelopes = true
+	client, err := tradera.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+
+	if _, err := client.Public().FetchToken(context.Background(), 42, "login-secret"); err != nil {
+		t.Fatal(err)
+	}
+
+	output := logged.String()
+	if !strings.Contains(output, "FetchToken") {
+		t.Fatalf("envelopes were not logged:\n%s", output)
+	}
+	for _, secret := range []string{"app-secret", "login-secret", "user-token"} {
+		if strings.Contains(output, secret) {
+			t.Fatalf("log leaks %q:\n%s", secret, output)
+		}
+	}
+}
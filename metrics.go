@@ -0,0 +1,313 @@
+package tradera
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/SebbeJohansson/tradera-go-client/middleware"
+)
+
+// Metrics receives measurements from the client's middleware.
+// Implementations must be safe for concurrent use.
+type Metrics interface {
+	// ObserveCall records a finished operation. errType is empty on success,
+	// otherwise a short class such as "rate_limited", "timeout" or "soap_fault".
+	ObserveCall(service, action string, duration time.Duration, errType string)
+
+	// ObserveRetries records the number of retries an operation needed.
+	ObserveRetries(service, action string, retries int)
+
+	// ObserveCache records a cache lookup.
+	ObserveCache(service, action string, hit bool)
+
+	// ObserveRateLimitWait records how long an operation waited for the rate limiter.
+	ObserveRateLimitWait(service, action string, wait time.Duration)
+
+	// ObserveRateLimitTokens records the tokens left in the rate limiter bucket.
+	ObserveRateLimitTokens(tokens float64)
+
+	// ObserveQuota records the current usage of a quota.
+	ObserveQuota(usage middleware.QuotaUsage)
+}
+
+// NoopMetrics is a Metrics implementation that records nothing.
+type NoopMetrics struct{}
+
+func (NoopMetrics) ObserveCall(service, action string, duration time.Duration, errType string) {}
+func (NoopMetrics) ObserveRetries(service, action string, retries int)                         {}
+func (NoopMetrics) ObserveCache(service, action string, hit bool)                              {}
+func (NoopMetrics) ObserveRateLimitWait(service, action string, wait time.Duration)            {}
+func (NoopMetrics) ObserveRateLimitTokens(tokens float64)                                      {}
+func (NoopMetrics) ObserveQuota(usage middleware.QuotaUsage)                                   {}
+
+// durationBuckets are the upper bounds (in seconds) of the latency histogram.
+var durationBuckets = []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30}
+
+// opKey identifies an operation in the collector.
+type opKey struct {
+	service string
+	action  string
+}
+
+// opMetrics holds the measurements of one operation.
+type opMetrics struct {
+	requests      uint64
+	errors        map[string]uint64 // by error type
+	buckets       []uint64          // cumulative counts per durationBuckets entry
+	durationSum   float64
+	retries       uint64
+	cacheHits     uint64
+	cacheMisses   uint64
+	rateLimitWait float64 // seconds
+}
+
+// MetricsCollector is a built-in Metrics implementation that keeps counters
+// in memory and serves them in the Prometheus text exposition format.
+type MetricsCollector struct {
+	mu              sync.Mutex
+	ops             map[opKey]*opMetrics
+	rateLimitTokens float64
+	hasTokens       bool
+	quotas          map[string]middleware.QuotaUsage
+}
+
+// NewMetricsCollector creates an empty metrics collector.
+func NewMetricsCollector() *MetricsCollector {
+	return &MetricsCollector{
+		ops:    make(map[opKey]*opMetrics),
+		quotas: make(map[string]middleware.QuotaUsage),
+	}
+}
+
+// op returns the metrics for an operation, creating them if needed.
+// Must be called with mutex held.
+func (m *MetricsCollector) op(service, action string) *opMetrics {
+	key := opKey{service, action}
+	om, ok := m.ops[key]
+	if !ok {
+		om = &opMetrics{
+			errors:  make(map[string]uint64),
+			buckets: make([]uint64, len(durationBuckets)),
+		}
+		m.ops[key] = om
+	}
+	return om
+}
+
+// ObserveCall records a finished operation.
+func (m *MetricsCollector) ObserveCall(service, action string, duration time.Duration, errType string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	om := m.op(service, action)
+	om.requests++
+	if errType != "" {
+		om.errors[errType]++
+	}
+
+	seconds := duration.Seconds()
+	om.durationSum += seconds
+	for i, bound := range durationBuckets {
+		if seconds <= bound {
+			om.buckets[i]++
+		}
+	}
+}
+
+// ObserveRetries records the number of retries an operation needed.
+func (m *MetricsCollector) ObserveRetries(service, action string, retries int) {
+	m.mu.Lock()
+	m.op(service, action).retries += uint64(retries)
+	m.mu.Unlock()
+}
+
+// ObserveCache records a cache lookup.
+func (m *MetricsCollector) ObserveCache(service, action string, hit bool) {
+	m.mu.Lock()
+	om := m.op(service, action)
+	if hit {
+		om.cacheHits++
+	} else {
+		om.cacheMisses++
+	}
+	m.mu.Unlock()
+}
+
+// ObserveRateLimitWait records how long an operation waited for the rate limiter.
+func (m *MetricsCollector) ObserveRateLimitWait(service, action string, wait time.Duration) {
+	m.mu.Lock()
+	m.op(service, action).rateLimitWait += wait.Seconds()
+	m.mu.Unlock()
+}
+
+// ObserveRateLimitTokens records the tokens left in the rate limiter bucket.
+func (m *MetricsCollector) ObserveRateLimitTokens(tokens float64) {
+	m.mu.Lock()
+	m.rateLimitTokens = tokens
+	m.hasTokens = true
+	m.mu.Unlock()
+}
+
+// ObserveQuota records the current usage of a quota.
+func (m *MetricsCollector) ObserveQuota(usage middleware.QuotaUsage) {
+	m.mu.Lock()
+	m.quotas[usage.Service] = usage
+	m.mu.Unlock()
+}
+
+// Handler returns an http.Handler serving the metrics in the Prometheus
+// text exposition format.
+func (m *MetricsCollector) Handler() http.Handler {
+	return m
+}
+
+// ServeHTTP implements http.Handler.
+func (m *MetricsCollector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	m.WriteText(w)
+}
+
+// WriteText writes the metrics in the Prometheus text exposition format.
+func (m *MetricsCollector) WriteText(out io.Writer) error {
+	w := bufio.NewWriter(out)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	keys := make([]opKey, 0, len(m.ops))
+	for key := range m.ops {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].service != keys[j].service {
+			return keys[i].service < keys[j].service
+		}
+		return keys[i].action < keys[j].action
+	})
+
+	writeMetricHeader(w, "tradera_requests_total", "counter", "Total Tradera API operations.")
+	for _, key := range keys {
+		writeMetricSample(w, "tradera_requests_total", opMetricLabels(key), float64(m.ops[key].requests))
+	}
+
+	writeMetricHeader(w, "tradera_request_errors_total", "counter", "Failed Tradera API operations by error type.")
+	for _, key := range keys {
+		om := m.ops[key]
+		types := make([]string, 0, len(om.errors))
+		for t := range om.errors {
+			types = append(types, t)
+		}
+		sort.Strings(types)
+		for _, t := range types {
+			writeMetricSample(w, "tradera_request_errors_total", opMetricLabels(key, "error_type", t), float64(om.errors[t]))
+		}
+	}
+
+	writeMetricHeader(w, "tradera_request_duration_seconds", "histogram", "Latency of Tradera API operations.")
+	for _, key := range keys {
+		om := m.ops[key]
+		for i, bound := range durationBuckets {
+			le := strconv.FormatFloat(bound, 'g', -1, 64)
+			writeMetricSample(w, "tradera_request_duration_seconds_bucket", opMetricLabels(key, "le", le), float64(om.buckets[i]))
+		}
+		writeMetricSample(w, "tradera_request_duration_seconds_bucket", opMetricLabels(key, "le", "+Inf"), float64(om.requests))
+		writeMetricSample(w, "tradera_request_duration_seconds_sum", opMetricLabels(key), om.durationSum)
+		writeMetricSample(w, "tradera_request_duration_seconds_count", opMetricLabels(key), float64(om.requests))
+	}
+
+	writeMetricHeader(w, "tradera_retries_total", "counter", "Retries of Tradera API operations.")
+	for _, key := range keys {
+		writeMetricSample(w, "tradera_retries_total", opMetricLabels(key), float64(m.ops[key].retries))
+	}
+
+	writeMetricHeader(w, "tradera_cache_requests_total", "counter", "Cache lookups by result.")
+	var hits, misses uint64
+	for _, key := range keys {
+		om := m.ops[key]
+		if om.cacheHits+om.cacheMisses == 0 {
+			continue
+		}
+		writeMetricSample(w, "tradera_cache_requests_total", opMetricLabels(key, "result", "hit"), float64(om.cacheHits))
+		writeMetricSample(w, "tradera_cache_requests_total", opMetricLabels(key, "result", "miss"), float64(om.cacheMisses))
+		hits += om.cacheHits
+		misses += om.cacheMisses
+	}
+
+	writeMetricHeader(w, "tradera_cache_hit_ratio", "gauge", "Fraction of cache lookups that were hits.")
+	ratio := 0.0
+	if hits+misses > 0 {
+		ratio = float64(hits) / float64(hits+misses)
+	}
+	writeMetricSample(w, "tradera_cache_hit_ratio", "", ratio)
+
+	writeMetricHeader(w, "tradera_ratelimit_wait_seconds_total", "counter", "Time spent waiting for the rate limiter.")
+	for _, key := range keys {
+		writeMetricSample(w, "tradera_ratelimit_wait_seconds_total", opMetricLabels(key), m.ops[key].rateLimitWait)
+	}
+
+	if m.hasTokens {
+		writeMetricHeader(w, "tradera_ratelimit_tokens", "gauge", "Tokens available in the rate limiter bucket.")
+		writeMetricSample(w, "tradera_ratelimit_tokens", "", m.rateLimitTokens)
+	}
+
+	if len(m.quotas) > 0 {
+		names := make([]string, 0, len(m.quotas))
+		for name := range m.quotas {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		writeMetricHeader(w, "tradera_quota_used", "gauge", "Calls counted in the current quota window.")
+		for _, name := range names {
+			writeMetricSample(w, "tradera_quota_used", metricLabels("quota", name), float64(m.quotas[name].Used))
+		}
+		writeMetricHeader(w, "tradera_quota_limit", "gauge", "Calls allowed per quota window.")
+		for _, name := range names {
+			writeMetricSample(w, "tradera_quota_limit", metricLabels("quota", name), float64(m.quotas[name].Limit))
+		}
+	}
+
+	return w.Flush()
+}
+
+// writeMetricHeader writes the HELP and TYPE lines of a metric family.
+func writeMetricHeader(w *bufio.Writer, name, typ, help string) {
+	fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, typ)
+}
+
+// writeMetricSample writes one sample line.
+func writeMetricSample(w *bufio.Writer, name, labels string, value float64) {
+	fmt.Fprintf(w, "%s%s %s\n", name, labels, strconv.FormatFloat(value, 'g', -1, 64))
+}
+
+// opMetricLabels formats the labels of an operation plus any extra label pairs.
+func opMetricLabels(key opKey, extra ...string) string {
+	return metricLabels(append([]string{"service", key.service, "action", key.action}, extra...)...)
+}
+
+// metricLabels formats label pairs as {k="v",...}.
+func metricLabels(pairs ...string) string {
+	var b strings.Builder
+	b.WriteByte('{')
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if i > 0 {
+			b.WriteByte(',')
+		}
+		b.WriteString(pairs[i])
+		b.WriteString(`="`)
+		b.WriteString(labelEscaper.Replace(pairs[i+1]))
+		b.WriteByte('"')
+	}
+	b.WriteByte('}')
+	return b.String()
+}
+
+// labelEscaper escapes label values for the text exposition format.
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
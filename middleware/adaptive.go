@@ -0,0 +1,137 @@
+package middleware
+
+import "time"
+
+// AdaptiveConfig configures adaptive rate limiting (AIMD): the rate is cut
+// multiplicatively when the API throttles and raised additively after a run
+// of successful calls.
+type AdaptiveConfig struct {
+	// MinRate is the lowest rate the limiter will drop to (requests per second)
+	// (default: 10% of MaxRate).
+	MinRate float64
+
+	// MaxRate is the highest rate the limiter will climb to (requests per second)
+	// (default: the limiter's rate when SetAdaptive is called).
+	MaxRate float64
+
+	// DecreaseFactor is multiplied into the rate on throttling (default: 0.5).
+	DecreaseFactor float64
+
+	// IncreaseStep is added to the rate after SuccessThreshold successes
+	// (default: 5% of MaxRate).
+	IncreaseStep float64
+
+	// SuccessThreshold is the number of consecutive successes required
+	// before the rate is raised (default: 10).
+	SuccessThreshold int
+
+	// Cooldown is the minimum time between two decreases, so a burst of
+	// concurrent failures only counts once (default: 1s).
+	Cooldown time.Duration
+}
+
+// adaptiveState tracks the feedback loop of an adaptive limiter.
+type adaptiveState struct {
+	config       AdaptiveConfig
+	successes    int
+	lastDecrease time.Time
+}
+
+// SetAdaptive enables adaptive mode with the given configuration.
+// The current rate is clamped to [MinRate, MaxRate], so it never drops to zero.
+func (r *RateLimiter) SetAdaptive(config AdaptiveConfig) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if config.MaxRate <= 0 {
+		config.MaxRate = r.rate
+	}
+	if config.MaxRate <= 0 {
+		config.MaxRate = 1
+	}
+	if config.MinRate <= 0 {
+		config.MinRate = config.MaxRate * 0.1
+	}
+	if config.MaxRate < config.MinRate {
+		config.MaxRate = config.MinRate
+	}
+	if config.DecreaseFactor <= 0 || config.DecreaseFactor >= 1 {
+		config.DecreaseFactor = 0.5
+	}
+	if config.IncreaseStep <= 0 {
+		config.IncreaseStep = config.MaxRate * 0.05
+	}
+	if config.SuccessThreshold <= 0 {
+		config.SuccessThreshold = 10
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = time.Second
+	}
+
+	r.adaptive = &adaptiveState{config: config}
+	r.refillTokens()
+	r.rate = min(max(r.rate, config.MinRate), config.MaxRate)
+	r.dispatch()
+}
+
+// OnSuccess reports a successful call. In adaptive mode the rate is raised
+// by IncreaseStep after SuccessThreshold consecutive successes.
+func (r *RateLimiter) OnSuccess() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a := r.adaptive
+	if a == nil {
+		return
+	}
+
+	a.successes++
+	if a.successes < a.config.SuccessThreshold {
+		return
+	}
+	a.successes = 0
+
+	if r.rate < a.config.MaxRate {
+		r.refillTokens()
+		r.rate = min(r.rate+a.config.IncreaseStep, a.config.MaxRate)
+		r.dispatch()
+	}
+}
+
+// OnThrottled reports a call rejected by the API for exceeding its limits.
+// In adaptive mode the rate is multiplied by DecreaseFactor, at most once per Cooldown.
+func (r *RateLimiter) OnThrottled() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	a := r.adaptive
+	if a == nil {
+		return
+	}
+
+	a.successes = 0
+
+	now := time.Now()
+	if now.Sub(a.lastDecrease) < a.config.Cooldown {
+		return
+	}
+	a.lastDecrease = now
+
+	r.refillTokens()
+	r.rate = max(r.rate*a.config.DecreaseFactor, a.config.MinRate)
+	r.dispatch()
+}
+
+// EffectiveRate returns the rate currently enforced, which in adaptive mode
+// may differ from the configured rate.
+func (r *RateLimiter) EffectiveRate() float64 {
+	return r.Rate()
+}
+
+// IsAdaptive reports whether adaptive mode is enabled.
+func (r *RateLimiter) IsAdaptive() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return r.adaptive != nil
+}
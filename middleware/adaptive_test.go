@@ -0,0 +1,56 @@
+package middleware
+
+import "testing"
+
+func TestSetAdaptiveZeroConfig(t *testing.T) {
+	r := NewRateLimiter(4)
+	r.SetAdaptive(AdaptiveConfig{})
+
+	if got := r.EffectiveRate(); got != 4 {
+		t.Fatalf("EffectiveRate() = %v after SetAdaptive, want the configured 4", got)
+	}
+
+	// Keep throttling well past the cooldown: the rate must bottom out above zero
+	for range 20 {
+		r.mu.Lock()
+		r.adaptive.lastDecrease = r.adaptive.lastDecrease.Add(-r.adaptive.config.Cooldown)
+		r.mu.Unlock()
+		r.OnThrottled()
+	}
+	if got := r.EffectiveRate(); got != 0.4 {
+		t.Fatalf("EffectiveRate() = %v after repeated throttling, want MinRate 0.4", got)
+	}
+
+	for range 10 * 100 {
+		r.OnSuccess()
+	}
+	if got := r.EffectiveRate(); got != 4 {
+		t.Fatalf("EffectiveRate() = %v after recovering, want MaxRate 4", got)
+	}
+}
+
+func TestSetAdaptiveClampsRate(t *testing.T) {
+	tests := []struct {
+		name     string
+		rate     float64
+		config   AdaptiveConfig
+		wantRate float64
+	}{
+		{"within bounds", 5, AdaptiveConfig{MinRate: 1, MaxRate: 10}, 5},
+		{"above max", 20, AdaptiveConfig{MinRate: 1, MaxRate: 10}, 10},
+		{"below min", 0.5, AdaptiveConfig{MinRate: 1, MaxRate: 10}, 1},
+		{"only min", 5, AdaptiveConfig{MinRate: 1}, 5},
+		{"only max", 5, AdaptiveConfig{MaxRate: 2}, 2},
+		{"zero limiter rate", 0, AdaptiveConfig{}, 0.1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := NewRateLimiter(tt.rate)
+			r.SetAdaptive(tt.config)
+			if got := r.EffectiveRate(); got != tt.wantRate {
+				t.Fatalf("EffectiveRate() = %v, want %v", got, tt.wantRate)
+			}
+		})
+	}
+}
@@ -2,9 +2,68 @@ package middleware
 
 import (
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// CacheStore is the interface implemented by cache backends.
+// Implementations must be safe for concurrent use.
+type CacheStore interface {
+	// Get retrieves a value. Returns false if the key is missing or expired.
+	Get(key string) (interface{}, bool)
+
+	// Set stores a value with the store's default TTL.
+	Set(key string, value interface{})
+
+	// SetWithTTL stores a value with a custom TTL.
+	SetWithTTL(key string, value interface{}, ttl time.Duration)
+
+	// Delete removes a value.
+	Delete(key string)
+
+	// Clear removes all values.
+	Clear()
+
+	// Stats returns a snapshot of the store's hit, miss and eviction counters.
+	Stats() CacheStats
+
+	// Close releases any resources held by the store.
+	Close()
+}
+
+// CacheStats holds cache usage statistics.
+type CacheStats struct {
+	Hits      uint64
+	Misses    uint64
+	Evictions uint64 // entries removed because they expired or exceeded a size bound
+	Entries   int
+	Bytes     int64 // approximate size of stored values, if tracked by the store
+}
+
+// HitRatio returns the fraction of lookups that were hits.
+func (s CacheStats) HitRatio() float64 {
+	total := s.Hits + s.Misses
+	if total == 0 {
+		return 0
+	}
+	return float64(s.Hits) / float64(total)
+}
+
+// cacheCounters tracks hit, miss and eviction counts for a store.
+type cacheCounters struct {
+	hits      atomic.Uint64
+	misses    atomic.Uint64
+	evictions atomic.Uint64
+}
+
+func (c *cacheCounters) snapshot() CacheStats {
+	return CacheStats{
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
 // CacheEntry represents a cached value with expiration.
 type CacheEntry struct {
 	Value      interface{}
@@ -16,11 +75,14 @@ func (e CacheEntry) IsExpired() bool {
 	return time.Now().After(e.Expiration)
 }
 
-// Cache provides in-memory caching with TTL support.
+// Cache provides unbounded in-memory caching with TTL support.
+// Use LRUCache when the number or size of entries must be bounded.
 type Cache struct {
 	defaultTTL time.Duration
 	entries    map[string]CacheEntry
 	mu         sync.RWMutex
+	counters   cacheCounters
+	group      Group // deduplicates concurrent GetOrSet and GetOrSetTyped loads
 
 	// Cleanup configuration
 	cleanupInterval time.Duration
@@ -50,20 +112,24 @@ func (c *Cache) Get(key string) (interface{}, bool) {
 	c.mu.RUnlock()
 
 	if !exists {
+		c.counters.misses.Add(1)
 		return nil, false
 	}
 
 	if entry.IsExpired() {
 		// Lazy deletion
 		c.Delete(key)
+		c.counters.misses.Add(1)
+		c.counters.evictions.Add(1)
 		return nil, false
 	}
 
+	c.counters.hits.Add(1)
 	return entry.Value, true
 }
 
-// GetTyped retrieves a typed value from the cache.
-func GetTyped[T any](c *Cache, key string) (T, bool) {
+// GetTyped retrieves a typed value from a cache store.
+func GetTyped[T any](c CacheStore, key string) (T, bool) {
 	var zero T
 	value, ok := c.Get(key)
 	if !ok {
@@ -126,6 +192,13 @@ func (c *Cache) Keys() []string {
 	return keys
 }
 
+// Stats returns the cache usage statistics.
+func (c *Cache) Stats() CacheStats {
+	stats := c.counters.snapshot()
+	stats.Entries = c.Size()
+	return stats
+}
+
 // cleanupLoop periodically removes expired entries.
 func (c *Cache) cleanupLoop() {
 	ticker := time.NewTicker(c.cleanupInterval)
@@ -148,6 +221,7 @@ func (c *Cache) cleanup() {
 	for key, entry := range c.entries {
 		if now.After(entry.Expiration) {
 			delete(c.entries, key)
+			c.counters.evictions.Add(1)
 		}
 	}
 	c.mu.Unlock()
@@ -160,26 +234,44 @@ func (c *Cache) Close() {
 
 // GetOrSet returns the cached value if it exists, otherwise calls the function
 // to compute the value, stores it in the cache, and returns it.
+// Concurrent calls for the same missing key share a single call to fn.
 func (c *Cache) GetOrSet(key string, fn func() (interface{}, error)) (interface{}, error) {
 	// Try to get from cache first
 	if value, ok := c.Get(key); ok {
 		return value, nil
 	}
 
-	// Compute the value
-	value, err := fn()
-	if err != nil {
-		return nil, err
-	}
+	value, err, _ := c.group.Do(key, func() (interface{}, error) {
+		// Compute the value
+		value, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		// Store in cache
+		c.Set(key, value)
+
+		return value, nil
+	})
 
-	// Store in cache
-	c.Set(key, value)
+	return value, err
+}
 
-	return value, nil
+// loadGrouper is implemented by stores that deduplicate concurrent
+// GetOrSetTyped loads through a Group of their own.
+type loadGrouper interface {
+	loadGroup() *Group
 }
 
-// GetOrSetTyped is a typed version of GetOrSet.
-func GetOrSetTyped[T any](c *Cache, key string, fn func() (T, error)) (T, error) {
+// loadGroup implements loadGrouper.
+func (c *Cache) loadGroup() *Group {
+	return &c.group
+}
+
+// GetOrSetTyped is a typed version of GetOrSet that works with any cache store.
+// Concurrent calls for the same missing key share a single call to fn for
+// the stores in this package; other stores call fn once per caller.
+func GetOrSetTyped[T any](c CacheStore, key string, fn func() (T, error)) (T, error) {
 	var zero T
 
 	// Try to get from cache first
@@ -187,14 +279,34 @@ func GetOrSetTyped[T any](c *Cache, key string, fn func() (T, error)) (T, error)
 		return value, nil
 	}
 
-	// Compute the value
-	value, err := fn()
+	load := func() (interface{}, error) {
+		// Another caller may have stored the value while we waited
+		if value, ok := GetTyped[T](c, key); ok {
+			return value, nil
+		}
+
+		// Compute the value
+		value, err := fn()
+		if err != nil {
+			return nil, err
+		}
+
+		// Store in cache
+		c.Set(key, value)
+
+		return value, nil
+	}
+
+	var value interface{}
+	var err error
+	if grouper, ok := c.(loadGrouper); ok {
+		value, err, _ = grouper.loadGroup().Do(key, load)
+	} else {
+		value, err = load()
+	}
 	if err != nil {
 		return zero, err
 	}
-
-	// Store in cache
-	c.Set(key, value)
-
-	return value, nil
+	typed, _ := value.(T)
+	return typed, nil
 }
@@ -0,0 +1,232 @@
+package middleware
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/gob"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// diskEntry is the on-disk representation of a cached value.
+type diskEntry struct {
+	Key        string
+	Expiration time.Time
+	Value      interface{}
+}
+
+// DiskCache is a file-backed cache that survives process restarts.
+// Each entry is stored as a gob-encoded file in the cache directory.
+//
+// Values are stored as interface{}, so their concrete types must be
+// registered with RegisterCacheType (or gob.Register) before use.
+type DiskCache struct {
+	dir        string
+	defaultTTL time.Duration
+	mu         sync.Mutex
+	counters   cacheCounters
+	group      Group // deduplicates concurrent GetOrSetTyped loads
+
+	// OnError is called when Set or SetWithTTL cannot store a value, for
+	// example because its type is not registered. The value is then not
+	// cached. Use Put to get the error directly.
+	OnError func(key string, err error)
+}
+
+// RegisterCacheType registers the concrete type of value so it can be
+// stored in a DiskCache.
+func RegisterCacheType(value interface{}) {
+	gob.Register(value)
+}
+
+// NewDiskCache creates a disk cache in dir, creating the directory if needed.
+func NewDiskCache(dir string, defaultTTL time.Duration) (*DiskCache, error) {
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return nil, err
+	}
+
+	return &DiskCache{
+		dir:        dir,
+		defaultTTL: defaultTTL,
+	}, nil
+}
+
+// path returns the file path for a key.
+func (c *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return filepath.Join(c.dir, hex.EncodeToString(sum[:])+".gob")
+}
+
+// loadGroup implements loadGrouper.
+func (c *DiskCache) loadGroup() *Group {
+	return &c.group
+}
+
+// Get retrieves a value from the cache.
+// Unreadable or expired entries are removed and reported as misses.
+func (c *DiskCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path := c.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		c.counters.misses.Add(1)
+		return nil, false
+	}
+
+	var entry diskEntry
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil || entry.Key != key {
+		os.Remove(path)
+		c.counters.misses.Add(1)
+		return nil, false
+	}
+
+	if time.Now().After(entry.Expiration) {
+		os.Remove(path)
+		c.counters.misses.Add(1)
+		c.counters.evictions.Add(1)
+		return nil, false
+	}
+
+	c.counters.hits.Add(1)
+	return entry.Value, true
+}
+
+// DefaultTTL returns the TTL used by Set.
+func (c *DiskCache) DefaultTTL() time.Duration {
+	return c.defaultTTL
+}
+
+// Set stores a value in the cache with the default TTL.
+func (c *DiskCache) Set(key string, value interface{}) {
+	c.SetWithTTL(key, value, c.defaultTTL)
+}
+
+// SetWithTTL stores a value in the cache with a custom TTL.
+// Values that cannot be stored are reported to OnError and not cached.
+func (c *DiskCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	if err := c.Put(key, value, ttl); err != nil && c.OnError != nil {
+		c.OnError(key, err)
+	}
+}
+
+// Put stores a value in the cache with a custom TTL, returning an error if
+// it cannot be encoded or written.
+func (c *DiskCache) Put(key string, value interface{}, ttl time.Duration) error {
+	var buf bytes.Buffer
+	entry := diskEntry{
+		Key:        key,
+		Expiration: time.Now().Add(ttl),
+		Value:      value,
+	}
+	if err := gob.NewEncoder(&buf).Encode(&entry); err != nil {
+		return fmt.Errorf("cache: encoding entry %q: %w", key, err)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// Write to a temporary file and rename so readers never see partial data
+	tmp, err := os.CreateTemp(c.dir, "tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(buf.Bytes()); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return nil
+}
+
+// Delete removes a value from the cache.
+func (c *DiskCache) Delete(key string) {
+	c.mu.Lock()
+	os.Remove(c.path(key))
+	c.mu.Unlock()
+}
+
+// Clear removes all values from the cache.
+func (c *DiskCache) Clear() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range c.files() {
+		os.Remove(filepath.Join(c.dir, name))
+	}
+}
+
+// Prune removes all expired entries from disk and returns how many were removed.
+func (c *DiskCache) Prune() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	removed := 0
+	for _, name := range c.files() {
+		path := filepath.Join(c.dir, name)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		var entry diskEntry
+		if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&entry); err != nil || now.After(entry.Expiration) {
+			os.Remove(path)
+			removed++
+		}
+	}
+
+	c.counters.evictions.Add(uint64(removed))
+	return removed
+}
+
+// files returns the names of all entry files in the cache directory.
+// Must be called with mutex held.
+func (c *DiskCache) files() []string {
+	dirEntries, err := os.ReadDir(c.dir)
+	if err != nil {
+		return nil
+	}
+
+	names := make([]string, 0, len(dirEntries))
+	for _, e := range dirEntries {
+		if !e.IsDir() && strings.HasSuffix(e.Name(), ".gob") {
+			names = append(names, e.Name())
+		}
+	}
+	return names
+}
+
+// Stats returns the cache usage statistics.
+func (c *DiskCache) Stats() CacheStats {
+	stats := c.counters.snapshot()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, name := range c.files() {
+		if info, err := os.Stat(filepath.Join(c.dir, name)); err == nil {
+			stats.Entries++
+			stats.Bytes += info.Size()
+		}
+	}
+	return stats
+}
+
+// Close is a no-op; entries remain on disk for the next process.
+func (c *DiskCache) Close() {}
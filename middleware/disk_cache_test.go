@@ -0,0 +1,102 @@
+package middleware
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// diskValue is a value type registered for the disk cache tests.
+type diskValue struct {
+	Name  string
+	Count int
+}
+
+func init() {
+	RegisterCacheType(diskValue{})
+}
+
+// unregisteredValue is never registered, so a DiskCache cannot store it.
+type unregisteredValue struct {
+	Name string
+}
+
+func newTestDiskCache(t *testing.T, dir string) *DiskCache {
+	t.Helper()
+
+	c, err := NewDiskCache(dir, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(c.Close)
+	return c
+}
+
+func TestDiskCacheSurvivesReopen(t *testing.T) {
+	dir := t.TempDir()
+
+	c := newTestDiskCache(t, dir)
+	c.Set("leica", diskValue{Name: "Leica M6", Count: 3})
+	c.SetWithTTL("expired", diskValue{Name: "Zeiss"}, -time.Second)
+
+	reopened := newTestDiskCache(t, dir)
+	value, ok := reopened.Get("leica")
+	if !ok || value != (diskValue{Name: "Leica M6", Count: 3}) {
+		t.Fatalf("Get(leica) after reopening = %v, %v, want the stored value", value, ok)
+	}
+	if _, ok := reopened.Get("expired"); ok {
+		t.Fatal("expired entry was returned after reopening")
+	}
+	if _, ok := reopened.Get("missing"); ok {
+		t.Fatal("Get(missing) reported a hit")
+	}
+
+	stats := reopened.Stats()
+	if stats.Hits != 1 || stats.Misses != 2 || stats.Evictions != 1 || stats.Entries != 1 || stats.Bytes == 0 {
+		t.Fatalf("Stats() = %+v, want 1 hit, 2 misses, 1 eviction and 1 stored entry", stats)
+	}
+}
+
+func TestDiskCacheUnstorableValue(t *testing.T) {
+	c := newTestDiskCache(t, t.TempDir())
+
+	if err := c.Put("value", unregisteredValue{Name: "x"}, time.Minute); err == nil {
+		t.Fatal("Put of an unregistered type succeeded")
+	}
+
+	var reported []string
+	c.OnError = func(key string, err error) {
+		reported = append(reported, key)
+	}
+	c.Set("value", unregisteredValue{Name: "x"})
+	if len(reported) != 1 || reported[0] != "value" {
+		t.Fatalf("OnError reported %v, want [value]", reported)
+	}
+	if _, ok := c.Get("value"); ok {
+		t.Fatal("unstorable value was cached")
+	}
+}
+
+func TestDiskCachePrune(t *testing.T) {
+	dir := t.TempDir()
+	c := newTestDiskCache(t, dir)
+
+	c.Set("fresh", diskValue{Name: "fresh"})
+	c.SetWithTTL("old", diskValue{Name: "old"}, -time.Second)
+	if err := os.WriteFile(filepath.Join(dir, "corrupt.gob"), []byte("not gob"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	if removed := c.Prune(); removed != 2 {
+		t.Fatalf("Prune() removed %d entries, want the expired and the corrupt one", removed)
+	}
+	if stats := c.Stats(); stats.Entries != 1 {
+		t.Fatalf("Stats() after Prune = %+v, want 1 entry", stats)
+	}
+
+	c.Clear()
+	if stats := c.Stats(); stats.Entries != 0 {
+		t.Fatalf("Stats() after Clear = %+v, want no entries", stats)
+	}
+}
@@ -0,0 +1,168 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// fileBucket is the token bucket state shared through the lock file.
+type fileBucket struct {
+	Tokens     float64 `json:"tokens"`
+	LastUpdate int64   `json:"last_update"` // unix nanoseconds
+}
+
+// FileLimiter is a token bucket shared by all processes on a host that use
+// the same state file. Each acquisition locks the file, refills the bucket,
+// takes a token and writes the state back.
+//
+// All processes sharing a file should use the same rate and burst.
+type FileLimiter struct {
+	rate       float64
+	bucketSize float64
+	file       *os.File
+	mu         sync.Mutex // serializes goroutines of this process; flock is per file description
+}
+
+// NewFileLimiter opens (or creates) the shared bucket state at path.
+// It fails on platforms without file locking.
+func NewFileLimiter(path string, requestsPerSecond, burstCapacity float64) (*FileLimiter, error) {
+	if requestsPerSecond <= 0 {
+		return nil, fmt.Errorf("ratelimit: rate must be positive, got %v", requestsPerSecond)
+	}
+	if burstCapacity < 1 {
+		return nil, fmt.Errorf("ratelimit: burst capacity must be at least 1, got %v", burstCapacity)
+	}
+	if !fileLocking {
+		return nil, fmt.Errorf("ratelimit: file limiter: %w", errors.ErrUnsupported)
+	}
+
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FileLimiter{
+		rate:       requestsPerSecond,
+		bucketSize: burstCapacity,
+		file:       f,
+	}, nil
+}
+
+// Wait blocks until a token is available in the shared bucket or the context is cancelled.
+func (l *FileLimiter) Wait(ctx context.Context) error {
+	for {
+		wait, err := l.take()
+		if err != nil {
+			return err
+		}
+		if wait == 0 {
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(wait):
+			// Try again
+		}
+	}
+}
+
+// TryAcquire attempts to take a token from the shared bucket without blocking.
+func (l *FileLimiter) TryAcquire() (bool, error) {
+	wait, err := l.take()
+	return err == nil && wait == 0, err
+}
+
+// take tries to take one token. It returns 0 on success, or the time until
+// a token is expected to become available.
+func (l *FileLimiter) take() (time.Duration, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if err := lockFile(l.file); err != nil {
+		return 0, fmt.Errorf("ratelimit: locking %s: %w", l.file.Name(), err)
+	}
+	defer unlockFile(l.file)
+
+	bucket, err := l.read()
+	if err != nil {
+		return 0, err
+	}
+
+	// Refill based on the time elapsed since any process last touched the bucket
+	now := time.Now()
+	if bucket.LastUpdate == 0 {
+		bucket.Tokens = l.bucketSize
+	} else {
+		elapsed := now.Sub(time.Unix(0, bucket.LastUpdate)).Seconds()
+		bucket.Tokens = min(bucket.Tokens+max(elapsed, 0)*l.rate, l.bucketSize)
+	}
+	bucket.LastUpdate = now.UnixNano()
+
+	var wait time.Duration
+	if bucket.Tokens >= 1.0 {
+		bucket.Tokens -= 1.0
+	} else {
+		wait = time.Duration((1.0 - bucket.Tokens) / l.rate * float64(time.Second))
+	}
+
+	if err := l.write(bucket); err != nil {
+		return 0, err
+	}
+	return wait, nil
+}
+
+// read loads the bucket state from the file. An empty file is a fresh bucket.
+// Must be called with the file locked.
+func (l *FileLimiter) read() (fileBucket, error) {
+	var bucket fileBucket
+
+	if _, err := l.file.Seek(0, io.SeekStart); err != nil {
+		return bucket, err
+	}
+	data, err := io.ReadAll(l.file)
+	if err != nil {
+		return bucket, err
+	}
+	if len(data) == 0 {
+		return bucket, nil
+	}
+
+	if err := json.Unmarshal(data, &bucket); err != nil {
+		// A corrupt state file is reset rather than blocking every process
+		return fileBucket{}, nil
+	}
+	return bucket, nil
+}
+
+// write stores the bucket state in the file.
+// Must be called with the file locked.
+func (l *FileLimiter) write(bucket fileBucket) error {
+	data, err := json.Marshal(bucket)
+	if err != nil {
+		return err
+	}
+
+	if err := l.file.Truncate(0); err != nil {
+		return err
+	}
+	_, err = l.file.WriteAt(data, 0)
+	return err
+}
+
+// Rate returns the rate limit in requests per second.
+func (l *FileLimiter) Rate() float64 {
+	return l.rate
+}
+
+// Close closes the state file.
+func (l *FileLimiter) Close() error {
+	return l.file.Close()
+}
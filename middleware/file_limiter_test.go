@@ -0,0 +1,126 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestFileLimiter opens a FileLimiter on path, skipping the test on
+// platforms without file locking.
+func newTestFileLimiter(t *testing.T, path string, rate, burst float64) *FileLimiter {
+	t.Helper()
+
+	l, err := NewFileLimiter(path, rate, burst)
+	if errors.Is(err, errors.ErrUnsupported) {
+		t.Skip("file locking is not supported on this platform")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { l.Close() })
+	return l
+}
+
+func TestNewFileLimiterInvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limiter.json")
+	tests := []struct {
+		name  string
+		rate  float64
+		burst float64
+	}{
+		{"zero rate", 0, 1},
+		{"negative rate", -1, 1},
+		{"zero burst", 1, 0},
+		{"fractional burst", 1, 0.5},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if l, err := NewFileLimiter(path, tt.rate, tt.burst); err == nil {
+				l.Close()
+				t.Fatalf("NewFileLimiter(%v, %v) succeeded", tt.rate, tt.burst)
+			}
+		})
+	}
+}
+
+func TestFileLimiterSharedBurst(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limiter.json")
+
+	// Two limiters stand in for two processes sharing the file
+	limiters := []*FileLimiter{
+		newTestFileLimiter(t, path, 0.01, 5),
+		newTestFileLimiter(t, path, 0.01, 5),
+	}
+
+	acquired := 0
+	for i := range 20 {
+		ok, err := limiters[i%2].TryAcquire()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok {
+			acquired++
+		}
+	}
+	if acquired != 5 {
+		t.Fatalf("two limiters sharing a burst of 5 acquired %d tokens", acquired)
+	}
+}
+
+func TestFileLimiterSharedRate(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limiter.json")
+	limiters := []*FileLimiter{
+		newTestFileLimiter(t, path, 50, 1),
+		newTestFileLimiter(t, path, 50, 1),
+	}
+
+	// 10 tokens at 50 per second with a burst of 1 take at least 180ms together
+	start := time.Now()
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := limiters[i%2].Wait(context.Background()); err != nil {
+				t.Errorf("Wait: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if elapsed := time.Since(start); elapsed < 150*time.Millisecond {
+		t.Fatalf("10 waits at 50/s took %v, want the shared rate to hold them back", elapsed)
+	}
+}
+
+func TestFileLimiterCorruptFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "limiter.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	l := newTestFileLimiter(t, path, 0.01, 2)
+	for i := range 2 {
+		if ok, err := l.TryAcquire(); err != nil || !ok {
+			t.Fatalf("TryAcquire %d on a reset bucket = %v, %v, want a token", i+1, ok, err)
+		}
+	}
+	if ok, _ := l.TryAcquire(); ok {
+		t.Fatal("reset bucket held more than its burst")
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bucket fileBucket
+	if err := json.Unmarshal(data, &bucket); err != nil {
+		t.Fatalf("state file %q was not rewritten: %v", data, err)
+	}
+}
@@ -0,0 +1,21 @@
+//go:build !(darwin || dragonfly || freebsd || linux || netbsd || openbsd)
+
+package middleware
+
+import (
+	"errors"
+	"os"
+)
+
+// fileLocking reports whether lockFile is supported.
+const fileLocking = false
+
+// lockFile is not supported on this platform.
+func lockFile(f *os.File) error {
+	return errors.ErrUnsupported
+}
+
+// unlockFile is not supported on this platform.
+func unlockFile(f *os.File) error {
+	return errors.ErrUnsupported
+}
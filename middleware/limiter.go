@@ -0,0 +1,27 @@
+package middleware
+
+import "context"
+
+// Limiter limits the rate of API requests.
+// RateLimiter is the in-process implementation; FileLimiter shares one
+// bucket between processes on the same host.
+type Limiter interface {
+	// Wait blocks until the request may proceed or the context is cancelled.
+	Wait(ctx context.Context) error
+}
+
+// ThrottleObserver is implemented by limiters that adapt to API feedback,
+// such as a RateLimiter in adaptive mode.
+type ThrottleObserver interface {
+	// OnSuccess reports a successful call.
+	OnSuccess()
+
+	// OnThrottled reports a call rejected for exceeding the API's limits.
+	OnThrottled()
+}
+
+var (
+	_ Limiter          = (*RateLimiter)(nil)
+	_ ThrottleObserver = (*RateLimiter)(nil)
+	_ Limiter          = (*FileLimiter)(nil)
+)
@@ -0,0 +1,206 @@
+package middleware
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+func init() {
+	// Allow stale-while-revalidate entries to be stored in a DiskCache
+	RegisterCacheType(staleEntry{})
+}
+
+// staleEntry wraps a value stored in stale-while-revalidate mode.
+// The entry is stored with a TTL that covers the stale window, and
+// FreshUntil marks the point after which it must be refreshed.
+type staleEntry struct {
+	Value      interface{}
+	FreshUntil time.Time
+}
+
+// LoaderConfig holds configuration for a CacheLoader.
+type LoaderConfig struct {
+	// TTL is how long a loaded value is considered fresh.
+	// If 0, the store's default TTL is used and stale-while-revalidate is disabled.
+	TTL time.Duration
+
+	// StaleWhileRevalidate is how long after TTL an expired value may still be
+	// served while a single background refresh runs (0 = disabled).
+	StaleWhileRevalidate time.Duration
+
+	// RefreshTimeout bounds each background refresh (default: 30s).
+	RefreshTimeout time.Duration
+
+	// LoadTimeout bounds each shared load on a cache miss, or the first
+	// caller's deadline if that is later (default: 30s). Loads run detached
+	// from the callers' cancellation, so a caller that gives up does not
+	// fail the others waiting for the same key.
+	LoadTimeout time.Duration
+}
+
+// CacheLoader loads values through a cache store, coalescing concurrent
+// loads of the same key into a single call. With StaleWhileRevalidate set,
+// expired values are served immediately while one background refresh runs.
+//
+// A CacheLoader with a nil store only coalesces in-flight calls.
+type CacheLoader struct {
+	store  CacheStore
+	config LoaderConfig
+	group  Group
+
+	refreshing sync.Map // key -> struct{}, background refreshes in flight
+}
+
+// NewCacheLoader creates a loader on top of the given store (which may be nil).
+func NewCacheLoader(store CacheStore, config LoaderConfig) *CacheLoader {
+	if config.TTL <= 0 {
+		config.StaleWhileRevalidate = 0
+	}
+	if config.RefreshTimeout <= 0 {
+		config.RefreshTimeout = 30 * time.Second
+	}
+	if config.LoadTimeout <= 0 {
+		config.LoadTimeout = 30 * time.Second
+	}
+
+	return &CacheLoader{
+		store:  store,
+		config: config,
+	}
+}
+
+// Load returns the value for key, calling fn on a cache miss.
+// Concurrent callers for the same key share one call to fn, which keeps
+// running if the caller that started it is cancelled; each caller stops
+// waiting when its own ctx is done.
+// hit reports whether the value was served from the cache.
+func (l *CacheLoader) Load(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (value interface{}, hit bool, err error) {
+	if l.store != nil {
+		if cached, ok := l.store.Get(key); ok {
+			entry, isStale := cached.(staleEntry)
+			if !isStale {
+				return cached, true, nil
+			}
+			if time.Now().After(entry.FreshUntil) {
+				l.refresh(ctx, key, fn)
+			}
+			return entry.Value, true, nil
+		}
+	}
+
+	value, err = l.load(ctx, key, fn)
+	return value, false, err
+}
+
+// load calls fn through the group on a context detached from ctx's
+// cancellation, and waits for the result until ctx is done.
+func (l *CacheLoader) load(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	type result struct {
+		value interface{}
+		err   error
+	}
+	done := make(chan result, 1)
+
+	go func() {
+		value, err, _ := l.group.Do(key, func() (interface{}, error) {
+			deadline := time.Now().Add(l.config.LoadTimeout)
+			if d, ok := ctx.Deadline(); ok && d.After(deadline) {
+				deadline = d
+			}
+			loadCtx, cancel := context.WithDeadline(context.WithoutCancel(ctx), deadline)
+			defer cancel()
+
+			return l.fetch(loadCtx, key, fn)
+		})
+		done <- result{value, err}
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetch calls fn and stores a successful result.
+func (l *CacheLoader) fetch(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	value, err := fn(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if l.store != nil {
+		l.put(key, value)
+	}
+	return value, nil
+}
+
+// put writes a value to the store, wrapping it when serving stale values is enabled.
+func (l *CacheLoader) put(key string, value interface{}) {
+	switch {
+	case l.config.StaleWhileRevalidate > 0:
+		l.store.SetWithTTL(key, staleEntry{
+			Value:      value,
+			FreshUntil: time.Now().Add(l.config.TTL),
+		}, l.config.TTL+l.config.StaleWhileRevalidate)
+	case l.config.TTL > 0:
+		l.store.SetWithTTL(key, value, l.config.TTL)
+	default:
+		l.store.Set(key, value)
+	}
+}
+
+// refresh starts a background refresh of key unless one is already running.
+// The refresh is detached from the caller's cancellation but keeps its values.
+func (l *CacheLoader) refresh(ctx context.Context, key string, fn func(context.Context) (interface{}, error)) {
+	if _, running := l.refreshing.LoadOrStore(key, struct{}{}); running {
+		return
+	}
+
+	go func() {
+		defer l.refreshing.Delete(key)
+
+		refreshCtx, cancel := context.WithTimeout(context.WithoutCancel(ctx), l.config.RefreshTimeout)
+		defer cancel()
+
+		l.group.Do(key, func() (interface{}, error) {
+			return l.fetch(refreshCtx, key, fn)
+		})
+	}()
+}
+
+// Invalidate removes key from the underlying store.
+func (l *CacheLoader) Invalidate(key string) {
+	if l.store != nil {
+		l.store.Delete(key)
+	}
+}
+
+// LoadTyped is a typed version of CacheLoader.Load.
+func LoadTyped[T any](ctx context.Context, l *CacheLoader, key string, fn func(context.Context) (T, error)) (T, bool, error) {
+	var zero T
+
+	value, hit, err := l.Load(ctx, key, func(ctx context.Context) (interface{}, error) {
+		return fn(ctx)
+	})
+	if err != nil {
+		return zero, hit, err
+	}
+
+	typed, ok := value.(T)
+	if !ok {
+		// A value of an unexpected type is treated as a miss
+		l.Invalidate(key)
+		value, err = l.load(ctx, key, func(ctx context.Context) (interface{}, error) {
+			return fn(ctx)
+		})
+		if err != nil {
+			return zero, false, err
+		}
+		typed, _ = value.(T)
+		return typed, false, nil
+	}
+	return typed, hit, nil
+}
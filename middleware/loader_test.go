@@ -0,0 +1,193 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// waitUntil polls cond until it holds, failing the test after a second.
+func waitUntil(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting until %s", what)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// joined returns how many callers are waiting on the in-flight call for key.
+func joined(g *Group, key string) int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if c, ok := g.calls[key]; ok {
+		return c.dups
+	}
+	return 0
+}
+
+// blockingLoad is a load function that counts its calls and returns value
+// once release is closed.
+type blockingLoad struct {
+	calls   atomic.Int32
+	release chan struct{}
+	value   string
+}
+
+func newBlockingLoad(value string) *blockingLoad {
+	return &blockingLoad{release: make(chan struct{}), value: value}
+}
+
+func (b *blockingLoad) fn(ctx context.Context) (interface{}, error) {
+	b.calls.Add(1)
+	select {
+	case <-b.release:
+		return b.value, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func TestCacheLoaderCoalescesMisses(t *testing.T) {
+	l := NewCacheLoader(NewCache(time.Minute), LoaderConfig{TTL: time.Minute})
+	load := newBlockingLoad("value")
+
+	var wg sync.WaitGroup
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, _, err := l.Load(context.Background(), "key", load.fn)
+			if err != nil || value != "value" {
+				t.Errorf("Load = %v, %v, want value", value, err)
+			}
+		}()
+	}
+
+	waitUntil(t, "the load starts", func() bool { return load.calls.Load() == 1 })
+	time.Sleep(10 * time.Millisecond)
+	close(load.release)
+	wg.Wait()
+
+	if n := load.calls.Load(); n != 1 {
+		t.Fatalf("50 concurrent misses ran %d loads, want 1", n)
+	}
+	if _, hit, _ := l.Load(context.Background(), "key", load.fn); !hit {
+		t.Fatal("loaded value was not cached")
+	}
+}
+
+func TestCacheLoaderStaleWhileRevalidate(t *testing.T) {
+	l := NewCacheLoader(NewCache(time.Minute), LoaderConfig{
+		TTL:                  10 * time.Millisecond,
+		StaleWhileRevalidate: time.Minute,
+	})
+
+	first := newBlockingLoad("old")
+	close(first.release)
+	if _, _, err := l.Load(context.Background(), "key", first.fn); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// Every caller gets the stale value at once, and only one refresh runs
+	refresh := newBlockingLoad("new")
+	for range 10 {
+		value, hit, err := l.Load(context.Background(), "key", refresh.fn)
+		if err != nil || !hit || value != "old" {
+			t.Fatalf("Load of a stale entry = %v, %v, %v, want the old value as a hit", value, hit, err)
+		}
+	}
+	waitUntil(t, "the refresh starts", func() bool { return refresh.calls.Load() == 1 })
+	close(refresh.release)
+
+	waitUntil(t, "the refreshed value is served", func() bool {
+		value, _, _ := l.Load(context.Background(), "key", refresh.fn)
+		return value == "new"
+	})
+	if n := refresh.calls.Load(); n != 1 {
+		t.Fatalf("stale reads started %d refreshes, want 1", n)
+	}
+}
+
+func TestCacheLoaderLoadSurvivesCancel(t *testing.T) {
+	l := NewCacheLoader(NewCache(time.Minute), LoaderConfig{TTL: time.Minute})
+	load := newBlockingLoad("value")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+	go func() {
+		_, _, err := l.Load(ctx, "key", load.fn)
+		firstErr <- err
+	}()
+	waitUntil(t, "the load starts", func() bool { return load.calls.Load() == 1 })
+
+	second := make(chan interface{}, 1)
+	go func() {
+		value, _, err := l.Load(context.Background(), "key", load.fn)
+		if err != nil {
+			t.Errorf("second Load: %v", err)
+		}
+		second <- value
+	}()
+	waitUntil(t, "the second caller joins", func() bool { return joined(&l.group, "key") == 1 })
+
+	// The first caller gives up without failing the shared load
+	cancel()
+	if err := <-firstErr; !errors.Is(err, context.Canceled) {
+		t.Fatalf("cancelled Load: err = %v, want context.Canceled", err)
+	}
+	close(load.release)
+
+	if value := <-second; value != "value" {
+		t.Fatalf("second Load = %v, want value", value)
+	}
+	if n := load.calls.Load(); n != 1 {
+		t.Fatalf("ran %d loads, want 1", n)
+	}
+}
+
+func TestCacheLoaderRefreshWaitsForRateLimiter(t *testing.T) {
+	l := NewCacheLoader(NewCache(time.Minute), LoaderConfig{
+		TTL:                  10 * time.Millisecond,
+		StaleWhileRevalidate: time.Minute,
+	})
+	limiter := newStalledLimiter()
+
+	var calls atomic.Int32
+	fn := func(ctx context.Context) (interface{}, error) {
+		if err := limiter.Wait(ctx); err != nil {
+			return nil, err
+		}
+		return int(calls.Add(1)), nil
+	}
+
+	grant(limiter, 1)
+	if _, _, err := l.Load(context.Background(), "key", fn); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	// The refresh keeps the caller's priority and outlives its context
+	ctx, cancel := context.WithCancel(WithPriority(context.Background(), PriorityLow))
+	if value, _, err := l.Load(ctx, "key", fn); err != nil || value != 1 {
+		t.Fatalf("Load of a stale entry = %v, %v, want 1", value, err)
+	}
+	cancel()
+	waitUntil(t, "the refresh waits for a token", func() bool { return limiter.Waiting(PriorityLow) == 1 })
+	if n := calls.Load(); n != 1 {
+		t.Fatalf("refresh ran without a token")
+	}
+
+	grant(limiter, 1)
+	waitUntil(t, "the refreshed value is served", func() bool {
+		value, _, _ := l.Load(context.Background(), "key", fn)
+		return value == 2
+	})
+}
@@ -0,0 +1,236 @@
+package middleware
+
+import (
+	"container/list"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// LRUConfig holds configuration for an LRUCache.
+type LRUConfig struct {
+	// DefaultTTL is the TTL used by Set.
+	DefaultTTL time.Duration
+
+	// MaxEntries is the maximum number of entries (0 = unbounded).
+	MaxEntries int
+
+	// MaxBytes is the maximum approximate size of all stored values (0 = unbounded).
+	MaxBytes int64
+
+	// Sizer returns the size in bytes of a value.
+	// If nil, EstimateSize is used.
+	Sizer func(key string, value interface{}) int64
+}
+
+// LRUCache is a bounded in-memory cache that evicts the least recently used
+// entries once MaxEntries or MaxBytes is exceeded.
+type LRUCache struct {
+	config   LRUConfig
+	ll       *list.List
+	entries  map[string]*list.Element
+	bytes    int64
+	mu       sync.Mutex
+	counters cacheCounters
+	group    Group // deduplicates concurrent GetOrSetTyped loads
+}
+
+type lruEntry struct {
+	key   string
+	entry CacheEntry
+	size  int64
+}
+
+// NewLRUCache creates a new LRU cache with the given configuration.
+func NewLRUCache(config LRUConfig) *LRUCache {
+	if config.Sizer == nil {
+		config.Sizer = func(key string, value interface{}) int64 {
+			return int64(len(key)) + EstimateSize(value)
+		}
+	}
+
+	return &LRUCache{
+		config:  config,
+		ll:      list.New(),
+		entries: make(map[string]*list.Element),
+	}
+}
+
+// loadGroup implements loadGrouper.
+func (c *LRUCache) loadGroup() *Group {
+	return &c.group
+}
+
+// Get retrieves a value from the cache and marks it as recently used.
+func (c *LRUCache) Get(key string) (interface{}, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.counters.misses.Add(1)
+		return nil, false
+	}
+
+	e := elem.Value.(*lruEntry)
+	if e.entry.IsExpired() {
+		c.removeElement(elem)
+		c.counters.misses.Add(1)
+		c.counters.evictions.Add(1)
+		return nil, false
+	}
+
+	c.ll.MoveToFront(elem)
+	c.counters.hits.Add(1)
+	return e.entry.Value, true
+}
+
+// Set stores a value in the cache with the default TTL.
+func (c *LRUCache) Set(key string, value interface{}) {
+	c.SetWithTTL(key, value, c.config.DefaultTTL)
+}
+
+// SetWithTTL stores a value in the cache with a custom TTL,
+// evicting least recently used entries if a bound is exceeded.
+func (c *LRUCache) SetWithTTL(key string, value interface{}, ttl time.Duration) {
+	size := c.config.Sizer(key, value)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+
+	// A value larger than the whole cache is never stored
+	if c.config.MaxBytes > 0 && size > c.config.MaxBytes {
+		c.counters.evictions.Add(1)
+		return
+	}
+
+	e := &lruEntry{
+		key: key,
+		entry: CacheEntry{
+			Value:      value,
+			Expiration: time.Now().Add(ttl),
+		},
+		size: size,
+	}
+	c.entries[key] = c.ll.PushFront(e)
+	c.bytes += size
+
+	for c.overLimit() {
+		oldest := c.ll.Back()
+		if oldest == nil {
+			break
+		}
+		c.removeElement(oldest)
+		c.counters.evictions.Add(1)
+	}
+}
+
+// overLimit reports whether the cache exceeds one of its bounds.
+// Must be called with mutex held.
+func (c *LRUCache) overLimit() bool {
+	if c.config.MaxEntries > 0 && c.ll.Len() > c.config.MaxEntries {
+		return true
+	}
+	if c.config.MaxBytes > 0 && c.bytes > c.config.MaxBytes {
+		return true
+	}
+	return false
+}
+
+// removeElement removes an element from the list and index.
+// Must be called with mutex held.
+func (c *LRUCache) removeElement(elem *list.Element) {
+	e := c.ll.Remove(elem).(*lruEntry)
+	delete(c.entries, e.key)
+	c.bytes -= e.size
+}
+
+// Delete removes a value from the cache.
+func (c *LRUCache) Delete(key string) {
+	c.mu.Lock()
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+	}
+	c.mu.Unlock()
+}
+
+// Clear removes all values from the cache.
+func (c *LRUCache) Clear() {
+	c.mu.Lock()
+	c.ll.Init()
+	c.entries = make(map[string]*list.Element)
+	c.bytes = 0
+	c.mu.Unlock()
+}
+
+// Stats returns the cache usage statistics.
+func (c *LRUCache) Stats() CacheStats {
+	stats := c.counters.snapshot()
+	c.mu.Lock()
+	stats.Entries = c.ll.Len()
+	stats.Bytes = c.bytes
+	c.mu.Unlock()
+	return stats
+}
+
+// Close is a no-op; LRUCache holds no background resources.
+func (c *LRUCache) Close() {}
+
+// EstimateSize returns the approximate memory footprint of a value in bytes.
+// It follows pointers, slices, maps and struct fields, counting each
+// pointer target only once.
+func EstimateSize(value interface{}) int64 {
+	if value == nil {
+		return 0
+	}
+	return estimateSize(reflect.ValueOf(value), make(map[uintptr]bool))
+}
+
+func estimateSize(v reflect.Value, seen map[uintptr]bool) int64 {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() || seen[v.Pointer()] {
+			return int64(v.Type().Size())
+		}
+		seen[v.Pointer()] = true
+		return int64(v.Type().Size()) + estimateSize(v.Elem(), seen)
+	case reflect.Interface:
+		if v.IsNil() {
+			return int64(v.Type().Size())
+		}
+		return int64(v.Type().Size()) + estimateSize(v.Elem(), seen)
+	case reflect.String:
+		return int64(v.Type().Size()) + int64(v.Len())
+	case reflect.Slice:
+		size := int64(v.Type().Size())
+		for i := 0; i < v.Len(); i++ {
+			size += estimateSize(v.Index(i), seen)
+		}
+		return size
+	case reflect.Array:
+		var size int64
+		for i := 0; i < v.Len(); i++ {
+			size += estimateSize(v.Index(i), seen)
+		}
+		return size
+	case reflect.Map:
+		size := int64(v.Type().Size())
+		iter := v.MapRange()
+		for iter.Next() {
+			size += estimateSize(iter.Key(), seen) + estimateSize(iter.Value(), seen)
+		}
+		return size
+	case reflect.Struct:
+		var size int64
+		for i := 0; i < v.NumField(); i++ {
+			size += estimateSize(v.Field(i), seen)
+		}
+		return size
+	default:
+		return int64(v.Type().Size())
+	}
+}
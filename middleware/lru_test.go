@@ -0,0 +1,137 @@
+package middleware
+
+import (
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// unitSizer counts every entry as one byte.
+func unitSizer(key string, value interface{}) int64 {
+	return 1
+}
+
+func TestLRUCacheMaxEntries(t *testing.T) {
+	c := NewLRUCache(LRUConfig{DefaultTTL: time.Minute, MaxEntries: 2})
+
+	c.Set("a", 1)
+	c.Set("b", 2)
+	// Reading a makes b the least recently used entry
+	if _, ok := c.Get("a"); !ok {
+		t.Fatal("a missing before the cache was full")
+	}
+	c.Set("c", 3)
+
+	if _, ok := c.Get("b"); ok {
+		t.Fatal("least recently used entry b was not evicted")
+	}
+	for _, key := range []string{"a", "c"} {
+		if _, ok := c.Get(key); !ok {
+			t.Fatalf("%s was evicted instead of b", key)
+		}
+	}
+
+	stats := c.Stats()
+	want := CacheStats{Hits: 3, Misses: 1, Evictions: 1, Entries: 2, Bytes: 2 * (1 + EstimateSize(1))}
+	if stats != want {
+		t.Fatalf("Stats() = %+v, want %+v", stats, want)
+	}
+}
+
+func TestLRUCacheMaxBytes(t *testing.T) {
+	sizes := map[string]int64{"small": 3, "medium": 5, "large": 11}
+	c := NewLRUCache(LRUConfig{
+		DefaultTTL: time.Minute,
+		MaxBytes:   10,
+		Sizer: func(key string, value interface{}) int64 {
+			return sizes[key]
+		},
+	})
+
+	c.Set("small", nil)
+	c.Set("medium", nil)
+	if stats := c.Stats(); stats.Bytes != 8 || stats.Entries != 2 {
+		t.Fatalf("Stats() = %+v, want 8 bytes in 2 entries", stats)
+	}
+
+	// A value larger than the whole cache is not stored and evicts nothing
+	c.Set("large", nil)
+	if _, ok := c.Get("large"); ok {
+		t.Fatal("value larger than MaxBytes was stored")
+	}
+	if stats := c.Stats(); stats.Bytes != 8 || stats.Entries != 2 || stats.Evictions != 1 {
+		t.Fatalf("Stats() = %+v, want 8 bytes in 2 entries and 1 eviction", stats)
+	}
+
+	// Growing an entry past the bound evicts the oldest one
+	sizes["medium"] = 9
+	c.Set("medium", nil)
+	if _, ok := c.Get("small"); ok {
+		t.Fatal("small was kept past MaxBytes")
+	}
+	if stats := c.Stats(); stats.Bytes != 9 || stats.Entries != 1 {
+		t.Fatalf("Stats() = %+v, want 9 bytes in 1 entry", stats)
+	}
+}
+
+func TestLRUCacheExpiry(t *testing.T) {
+	c := NewLRUCache(LRUConfig{DefaultTTL: time.Minute, Sizer: unitSizer})
+
+	c.SetWithTTL("old", 1, -time.Second)
+	c.Set("new", 2)
+
+	if _, ok := c.Get("old"); ok {
+		t.Fatal("expired entry was returned")
+	}
+	if value, ok := c.Get("new"); !ok || value != 2 {
+		t.Fatalf("Get(new) = %v, %v, want 2, true", value, ok)
+	}
+
+	stats := c.Stats()
+	want := CacheStats{Hits: 1, Misses: 1, Evictions: 1, Entries: 1, Bytes: 1}
+	if stats != want {
+		t.Fatalf("Stats() = %+v, want %+v", stats, want)
+	}
+
+	c.Delete("new")
+	c.Set("other", 3)
+	c.Clear()
+	if stats := c.Stats(); stats.Entries != 0 || stats.Bytes != 0 {
+		t.Fatalf("Stats() after Clear = %+v, want no entries", stats)
+	}
+}
+
+func TestLRUCacheGetOrSetTypedLoadsOnce(t *testing.T) {
+	c := NewLRUCache(LRUConfig{DefaultTTL: time.Minute})
+
+	var loads atomic.Int32
+	release := make(chan struct{})
+	var wg sync.WaitGroup
+	for range 20 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			value, err := GetOrSetTyped(c, "key", func() (int, error) {
+				loads.Add(1)
+				<-release
+				return 42, nil
+			})
+			if err != nil || value != 42 {
+				t.Errorf("GetOrSetTyped = %v, %v, want 42", value, err)
+			}
+		}()
+	}
+
+	// Let the callers reach the load before it finishes
+	for !c.group.InFlight("key") {
+		time.Sleep(time.Millisecond)
+	}
+	time.Sleep(10 * time.Millisecond)
+	close(release)
+	wg.Wait()
+
+	if n := loads.Load(); n != 1 {
+		t.Fatalf("20 concurrent misses ran %d loads, want 1", n)
+	}
+}
@@ -0,0 +1,59 @@
+package middleware
+
+import "context"
+
+// Priority is the importance of a request. Higher priorities are served
+// first by the rate limiter and may use reserved quota.
+type Priority int
+
+const (
+	// PriorityLow is for background work such as bulk exports.
+	PriorityLow Priority = iota
+
+	// PriorityNormal is the default priority.
+	PriorityNormal
+
+	// PriorityHigh is for interactive, user-facing requests.
+	PriorityHigh
+)
+
+// String returns the name of the priority.
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityNormal:
+		return "normal"
+	case PriorityHigh:
+		return "high"
+	default:
+		return "unknown"
+	}
+}
+
+// clamp limits the priority to the known range.
+func (p Priority) clamp() Priority {
+	if p < PriorityLow {
+		return PriorityLow
+	}
+	if p > PriorityHigh {
+		return PriorityHigh
+	}
+	return p
+}
+
+type priorityKey struct{}
+
+// WithPriority returns a copy of ctx carrying the given request priority.
+func WithPriority(ctx context.Context, p Priority) context.Context {
+	return context.WithValue(ctx, priorityKey{}, p)
+}
+
+// PriorityFromContext returns the priority carried by ctx,
+// or PriorityNormal if none is set.
+func PriorityFromContext(ctx context.Context) Priority {
+	if p, ok := ctx.Value(priorityKey{}).(Priority); ok {
+		return p.clamp()
+	}
+	return PriorityNormal
+}
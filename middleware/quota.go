@@ -0,0 +1,451 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// ErrQuotaExhausted is returned when a call would exceed the API quota.
+var ErrQuotaExhausted = errors.New("tradera: API quota exhausted")
+
+// QuotaAllServices is the quota key that limits calls across all services.
+const QuotaAllServices = "*"
+
+// quotaBuckets is the number of buckets a rolling window is divided into.
+const quotaBuckets = 96
+
+// QuotaError is returned when a quota has been used up.
+// It matches ErrQuotaExhausted with errors.Is.
+type QuotaError struct {
+	Service string        // quota key that was exhausted
+	Limit   int           // calls allowed per window
+	Used    int           // calls made in the current window
+	ResetIn time.Duration // time until budget becomes available again
+}
+
+// Error implements the error interface.
+func (e *QuotaError) Error() string {
+	return fmt.Sprintf("tradera: API quota exhausted for %s (%d/%d calls), resets in %s",
+		e.Service, e.Used, e.Limit, e.ResetIn.Round(time.Second))
+}
+
+// Is implements errors.Is for QuotaError.
+func (e *QuotaError) Is(target error) bool {
+	return target == ErrQuotaExhausted
+}
+
+// QuotaLimit configures the call budget for one quota key.
+type QuotaLimit struct {
+	// Limit is the maximum number of calls per window.
+	Limit int
+
+	// Window is the length of the rolling window (default: 24h).
+	Window time.Duration
+
+	// Reserved is the number of calls held back for PriorityHigh requests.
+	Reserved int
+}
+
+// QuotaBucket counts the calls made during one slice of a rolling window.
+type QuotaBucket struct {
+	Start time.Time `json:"start"`
+	Count int       `json:"count"`
+}
+
+// QuotaState is the persisted call history of one quota key.
+type QuotaState struct {
+	Buckets []QuotaBucket `json:"buckets"`
+}
+
+// QuotaUsage describes the current use of a quota.
+type QuotaUsage struct {
+	Service   string
+	Limit     int
+	Used      int
+	Remaining int
+	ResetIn   time.Duration // time until the oldest counted call leaves the window
+}
+
+// QuotaStore persists quota counters across restarts.
+// Implementations must be safe for concurrent use.
+type QuotaStore interface {
+	// Load returns the state for key, or an empty state if none is stored.
+	Load(key string) (QuotaState, error)
+
+	// Save stores the state for key.
+	Save(key string, state QuotaState) error
+}
+
+// QuotaUpdater is implemented by quota stores that can be shared between
+// processes. Update loads the states of keys, calls fn and saves the states
+// if fn returns nil, holding a lock that excludes other processes
+// throughout, so checking and counting a call is atomic.
+type QuotaUpdater interface {
+	Update(keys []string, fn func(states map[string]*QuotaState) error) error
+}
+
+// QuotaManager enforces call budgets per service over rolling windows.
+//
+// Counters are read from the store on every call rather than cached, so
+// processes sharing a FileQuotaStore see each other's calls.
+type QuotaManager struct {
+	limits map[string]QuotaLimit
+	store  QuotaStore
+	mu     sync.Mutex
+}
+
+// NewQuotaManager creates a quota manager with limits keyed by service name.
+// Use QuotaAllServices as a key to limit the total across services.
+// If store is nil, counters are kept in memory only.
+func NewQuotaManager(store QuotaStore, limits map[string]QuotaLimit) *QuotaManager {
+	if store == nil {
+		store = NewMemoryQuotaStore()
+	}
+
+	normalized := make(map[string]QuotaLimit, len(limits))
+	for key, limit := range limits {
+		if limit.Window <= 0 {
+			limit.Window = 24 * time.Hour
+		}
+		normalized[key] = limit
+	}
+
+	return &QuotaManager{
+		limits: normalized,
+		store:  store,
+	}
+}
+
+// Acquire records a call to service if budget is available.
+// Returns a *QuotaError if the service or overall quota is exhausted.
+// Requests with PriorityHigh in ctx may use reserved budget.
+func (q *QuotaManager) Acquire(ctx context.Context, service string) error {
+	priority := PriorityFromContext(ctx)
+	now := time.Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	keys := q.keysFor(service)
+	if len(keys) == 0 {
+		return nil
+	}
+
+	return q.update(keys, func(states map[string]*QuotaState) error {
+		// Check every applicable quota before counting against any of them
+		for _, key := range keys {
+			limit := q.limits[key]
+			state := states[key]
+
+			used := state.used(now, limit.Window)
+			available := limit.Limit
+			if priority < PriorityHigh {
+				available -= limit.Reserved
+			}
+
+			if used >= available {
+				return &QuotaError{
+					Service: key,
+					Limit:   limit.Limit,
+					Used:    used,
+					ResetIn: state.resetIn(now, limit.Window),
+				}
+			}
+		}
+
+		for _, key := range keys {
+			states[key].add(now, q.limits[key].Window)
+		}
+		return nil
+	})
+}
+
+// Usage returns the current usage of the quota for key.
+func (q *QuotaManager) Usage(key string) QuotaUsage {
+	now := time.Now()
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	limit, ok := q.limits[key]
+	if !ok {
+		return QuotaUsage{Service: key}
+	}
+
+	usage := QuotaUsage{Service: key, Limit: limit.Limit}
+	state, err := q.store.Load(key)
+	if err != nil {
+		return usage
+	}
+
+	usage.Used = state.used(now, limit.Window)
+	usage.Remaining = max(limit.Limit-usage.Used, 0)
+	usage.ResetIn = state.resetIn(now, limit.Window)
+	return usage
+}
+
+// Limits returns the configured quota keys and limits.
+func (q *QuotaManager) Limits() map[string]QuotaLimit {
+	limits := make(map[string]QuotaLimit, len(q.limits))
+	for key, limit := range q.limits {
+		limits[key] = limit
+	}
+	return limits
+}
+
+// keysFor returns the quota keys that apply to a call to service.
+func (q *QuotaManager) keysFor(service string) []string {
+	var keys []string
+	if _, ok := q.limits[service]; ok {
+		keys = append(keys, service)
+	}
+	if _, ok := q.limits[QuotaAllServices]; ok && service != QuotaAllServices {
+		keys = append(keys, QuotaAllServices)
+	}
+	return keys
+}
+
+// update runs fn on the states of keys and saves them if fn succeeds,
+// atomically across processes if the store is a QuotaUpdater.
+// Must be called with mutex held.
+func (q *QuotaManager) update(keys []string, fn func(states map[string]*QuotaState) error) error {
+	if updater, ok := q.store.(QuotaUpdater); ok {
+		return updater.Update(keys, fn)
+	}
+
+	states := make(map[string]*QuotaState, len(keys))
+	for _, key := range keys {
+		state, err := q.store.Load(key)
+		if err != nil {
+			return err
+		}
+		states[key] = &state
+	}
+	if err := fn(states); err != nil {
+		return err
+	}
+	for _, key := range keys {
+		if err := q.store.Save(key, *states[key]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// bucketWidth returns the width of one bucket in a window.
+func bucketWidth(window time.Duration) time.Duration {
+	width := window / quotaBuckets
+	if width <= 0 {
+		width = window
+	}
+	return width
+}
+
+// prune drops buckets that have fully left the window.
+func (s *QuotaState) prune(now time.Time, window time.Duration) {
+	width := bucketWidth(window)
+	kept := s.Buckets[:0]
+	for _, b := range s.Buckets {
+		if b.Start.Add(width + window).After(now) {
+			kept = append(kept, b)
+		}
+	}
+	s.Buckets = kept
+}
+
+// used returns the number of calls in the window ending at now.
+func (s *QuotaState) used(now time.Time, window time.Duration) int {
+	s.prune(now, window)
+	total := 0
+	for _, b := range s.Buckets {
+		total += b.Count
+	}
+	return total
+}
+
+// add counts one call at now.
+func (s *QuotaState) add(now time.Time, window time.Duration) {
+	s.prune(now, window)
+	start := now.Truncate(bucketWidth(window))
+	if n := len(s.Buckets); n > 0 && s.Buckets[n-1].Start.Equal(start) {
+		s.Buckets[n-1].Count++
+		return
+	}
+	s.Buckets = append(s.Buckets, QuotaBucket{Start: start, Count: 1})
+}
+
+// resetIn returns the time until the oldest bucket leaves the window.
+func (s *QuotaState) resetIn(now time.Time, window time.Duration) time.Duration {
+	if len(s.Buckets) == 0 {
+		return 0
+	}
+	return s.Buckets[0].Start.Add(bucketWidth(window) + window).Sub(now)
+}
+
+// MemoryQuotaStore keeps quota counters in memory.
+type MemoryQuotaStore struct {
+	states map[string]QuotaState
+	mu     sync.Mutex
+}
+
+// NewMemoryQuotaStore creates an in-memory quota store.
+func NewMemoryQuotaStore() *MemoryQuotaStore {
+	return &MemoryQuotaStore{states: make(map[string]QuotaState)}
+}
+
+// Load returns the state for key.
+func (m *MemoryQuotaStore) Load(key string) (QuotaState, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state := m.states[key]
+	state.Buckets = append([]QuotaBucket(nil), state.Buckets...)
+	return state, nil
+}
+
+// Save stores the state for key.
+func (m *MemoryQuotaStore) Save(key string, state QuotaState) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	state.Buckets = append([]QuotaBucket(nil), state.Buckets...)
+	m.states[key] = state
+	return nil
+}
+
+// FileQuotaStore persists quota counters as JSON in a single file, which
+// processes on the same host may share. Updates lock a sibling file with the
+// suffix ".lock" while they read, check and write the counters. On platforms
+// without file locking, only one process may use the file.
+type FileQuotaStore struct {
+	path string
+	mu   sync.Mutex // serializes goroutines of this process; flock is per file description
+}
+
+// NewFileQuotaStore creates a quota store backed by the file at path.
+// The file is created on the first save.
+func NewFileQuotaStore(path string) *FileQuotaStore {
+	return &FileQuotaStore{path: path}
+}
+
+// Load returns the state for key.
+func (f *FileQuotaStore) Load(key string) (QuotaState, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	states, err := f.read()
+	if err != nil {
+		return QuotaState{}, err
+	}
+	return states[key], nil
+}
+
+// Save stores the state for key.
+func (f *FileQuotaStore) Save(key string, state QuotaState) error {
+	return f.Update([]string{key}, func(states map[string]*QuotaState) error {
+		*states[key] = state
+		return nil
+	})
+}
+
+// Update implements QuotaUpdater.
+func (f *FileQuotaStore) Update(keys []string, fn func(states map[string]*QuotaState) error) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	unlock, err := f.lock()
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	all, err := f.read()
+	if err != nil {
+		return err
+	}
+	states := make(map[string]*QuotaState, len(keys))
+	for _, key := range keys {
+		state := all[key]
+		states[key] = &state
+	}
+	if err := fn(states); err != nil {
+		return err
+	}
+	for key, state := range states {
+		all[key] = *state
+	}
+	return f.write(all)
+}
+
+// lock takes the inter-process lock on the store, returning a function that
+// releases it. The data file itself is replaced on every write, so the lock
+// is held on a separate file.
+// Must be called with mutex held.
+func (f *FileQuotaStore) lock() (func(), error) {
+	if !fileLocking {
+		return func() {}, nil
+	}
+
+	lf, err := os.OpenFile(f.path+".lock", os.O_RDWR|os.O_CREATE, 0o600)
+	if err != nil {
+		return nil, err
+	}
+	if err := lockFile(lf); err != nil {
+		lf.Close()
+		return nil, fmt.Errorf("tradera: locking quota file %s: %w", f.path, err)
+	}
+	return func() {
+		unlockFile(lf)
+		lf.Close()
+	}, nil
+}
+
+// read loads all states from the file.
+// Must be called with mutex held.
+func (f *FileQuotaStore) read() (map[string]QuotaState, error) {
+	states := make(map[string]QuotaState)
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return states, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &states); err != nil {
+		return nil, fmt.Errorf("tradera: reading quota file %s: %w", f.path, err)
+	}
+	return states, nil
+}
+
+// write saves all states to the file.
+// Must be called with mutex held and the file locked.
+func (f *FileQuotaStore) write(states map[string]QuotaState) error {
+	data, err := json.Marshal(states)
+	if err != nil {
+		return err
+	}
+
+	// Write to a temporary file and rename so a crash never leaves a partial file
+	tmp, err := os.CreateTemp(filepath.Dir(f.path), filepath.Base(f.path)+".tmp-*")
+	if err != nil {
+		return err
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return err
+	}
+	return os.Rename(tmp.Name(), f.path)
+}
@@ -0,0 +1,154 @@
+package middleware
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestQuotaStateRollingWindow(t *testing.T) {
+	// A 96 minute window has one minute buckets
+	window := 96 * time.Minute
+	start := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+
+	var s QuotaState
+	s.add(start, window)
+	s.add(start.Add(30*time.Second), window)
+	s.add(start.Add(time.Minute), window)
+	if len(s.Buckets) != 2 || s.Buckets[0].Count != 2 || s.Buckets[1].Count != 1 {
+		t.Fatalf("buckets = %+v, want 2 calls in the first minute and 1 in the second", s.Buckets)
+	}
+
+	tests := []struct {
+		name    string
+		at      time.Duration
+		used    int
+		resetIn time.Duration
+	}{
+		{"within the window", 50 * time.Minute, 3, 47 * time.Minute},
+		{"first bucket partly out", window + 30*time.Second, 3, 30 * time.Second},
+		{"first bucket out", window + time.Minute, 1, time.Minute},
+		{"all out", window + 2*time.Minute, 0, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			state := QuotaState{Buckets: append([]QuotaBucket(nil), s.Buckets...)}
+			now := start.Add(tt.at)
+			if used := state.used(now, window); used != tt.used {
+				t.Fatalf("used = %d, want %d", used, tt.used)
+			}
+			if resetIn := state.resetIn(now, window); resetIn != tt.resetIn {
+				t.Fatalf("resetIn = %v, want %v", resetIn, tt.resetIn)
+			}
+		})
+	}
+}
+
+func TestQuotaManagerReserved(t *testing.T) {
+	q := NewQuotaManager(nil, map[string]QuotaLimit{
+		"search": {Limit: 3, Reserved: 1},
+	})
+	normal := context.Background()
+	high := WithPriority(context.Background(), PriorityHigh)
+
+	for i := range 2 {
+		if err := q.Acquire(normal, "search"); err != nil {
+			t.Fatalf("call %d: %v", i+1, err)
+		}
+	}
+
+	// The last call is held back for high priority requests
+	err := q.Acquire(normal, "search")
+	var quotaErr *QuotaError
+	if !errors.As(err, &quotaErr) || !errors.Is(err, ErrQuotaExhausted) {
+		t.Fatalf("normal call into the reserve: err = %v, want a *QuotaError", err)
+	}
+	if quotaErr.Service != "search" || quotaErr.Limit != 3 || quotaErr.Used != 2 {
+		t.Fatalf("QuotaError = %+v, want 2 of 3 calls used on search", *quotaErr)
+	}
+	if quotaErr.ResetIn <= 23*time.Hour || quotaErr.ResetIn > 24*time.Hour+bucketWidth(24*time.Hour) {
+		t.Fatalf("ResetIn = %v, want about a day", quotaErr.ResetIn)
+	}
+
+	if err := q.Acquire(high, "search"); err != nil {
+		t.Fatalf("high priority call: %v", err)
+	}
+	if err := q.Acquire(high, "search"); !errors.Is(err, ErrQuotaExhausted) {
+		t.Fatalf("high priority call past the limit: err = %v, want ErrQuotaExhausted", err)
+	}
+
+	usage := q.Usage("search")
+	if usage.Used != 3 || usage.Remaining != 0 {
+		t.Fatalf("Usage = %+v, want 3 used and none remaining", usage)
+	}
+}
+
+func TestQuotaManagerAllServices(t *testing.T) {
+	q := NewQuotaManager(nil, map[string]QuotaLimit{
+		"search":         {Limit: 5},
+		QuotaAllServices: {Limit: 2},
+	})
+
+	if err := q.Acquire(context.Background(), "search"); err != nil {
+		t.Fatal(err)
+	}
+	if err := q.Acquire(context.Background(), "public"); err != nil {
+		t.Fatal(err)
+	}
+
+	// A call rejected by the overall quota is not counted against search
+	var quotaErr *QuotaError
+	if err := q.Acquire(context.Background(), "search"); !errors.As(err, &quotaErr) || quotaErr.Service != QuotaAllServices {
+		t.Fatalf("third call: err = %v, want the overall quota exhausted", err)
+	}
+	if used := q.Usage("search").Used; used != 1 {
+		t.Fatalf("search used %d calls, want 1", used)
+	}
+}
+
+func TestFileQuotaStoreShared(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "quota.json")
+	limits := map[string]QuotaLimit{"search": {Limit: 20}}
+
+	// Two managers stand in for two processes sharing the file
+	managers := []*QuotaManager{
+		NewQuotaManager(NewFileQuotaStore(path), limits),
+		NewQuotaManager(NewFileQuotaStore(path), limits),
+	}
+
+	var granted atomic.Int32
+	var wg sync.WaitGroup
+	for i := range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := managers[i%2].Acquire(context.Background(), "search")
+			switch {
+			case err == nil:
+				granted.Add(1)
+			case !errors.Is(err, ErrQuotaExhausted):
+				t.Errorf("Acquire: %v", err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := granted.Load(); n != 20 {
+		t.Fatalf("two stores on one file granted %d calls, want 20", n)
+	}
+	for i, q := range managers {
+		if used := q.Usage("search").Used; used != 20 {
+			t.Fatalf("manager %d sees %d calls used, want 20", i, used)
+		}
+	}
+	if fileLocking {
+		if _, err := os.Stat(path + ".lock"); err != nil {
+			t.Fatalf("lock file: %v", err)
+		}
+	}
+}
@@ -2,17 +2,43 @@ package middleware
 
 import (
 	"context"
+	"fmt"
 	"sync"
 	"time"
 )
 
+// numPriorities is the number of priority lanes.
+const numPriorities = int(PriorityHigh) + 1
+
+// shareDecay is the factor by which per-lane grant counts decay on each grant,
+// so lane shares reflect roughly the last hundred grants.
+const shareDecay = 0.99
+
 // RateLimiter provides rate limiting for API requests using a token bucket algorithm.
+//
+// Waiters are served by priority: each call carries a Priority through its
+// context (see WithPriority), and higher-priority waiters are granted tokens
+// before lower-priority ones. Lanes can be given a minimum share of grants
+// with SetMinShare so that background work is never starved completely.
 type RateLimiter struct {
 	rate       float64    // tokens per second
 	bucketSize float64    // max tokens (burst capacity)
-	tokens     float64    // current tokens
+	tokens     float64    // current tokens (negative while reservations are outstanding)
 	lastUpdate time.Time  // last token update time
-	mu         sync.Mutex // protects tokens and lastUpdate
+	mu         sync.Mutex // protects all fields below
+
+	lanes    [numPriorities][]*waiter // FIFO queue of waiters per priority
+	minShare [numPriorities]float64   // guaranteed fraction of grants per lane
+	served   [numPriorities]float64   // decayed number of tokens granted per lane
+	timer    *time.Timer              // wakes the dispatcher when tokens accumulate
+	adaptive *adaptiveState           // non-nil in adaptive mode
+}
+
+// waiter is a caller blocked in WaitN.
+type waiter struct {
+	n       float64
+	ready   chan struct{}
+	granted bool
 }
 
 // NewRateLimiter creates a new rate limiter with the specified rate (requests per second).
@@ -34,32 +60,68 @@ func NewRateLimiterWithBurst(requestsPerSecond, burstCapacity float64) *RateLimi
 // Wait blocks until a token is available or the context is cancelled.
 // Returns nil if a token was acquired, or the context error if cancelled.
 func (r *RateLimiter) Wait(ctx context.Context) error {
-	for {
-		if r.TryAcquire() {
-			return nil
-		}
+	return r.WaitN(ctx, 1)
+}
 
-		// Calculate wait time until next token
-		waitTime := r.timeUntilNextToken()
+// WaitN blocks until n tokens are available or the context is cancelled.
+// Waiters with a higher priority in ctx are served first; within a priority
+// waiters are served in arrival order.
+// It returns an error if n is less than 1 or exceeds the burst capacity.
+func (r *RateLimiter) WaitN(ctx context.Context, n int) error {
+	if n < 1 {
+		return fmt.Errorf("ratelimit: requested %d tokens, want at least 1", n)
+	}
+	tokens := float64(n)
+
+	r.mu.Lock()
+	if tokens > r.bucketSize {
+		r.mu.Unlock()
+		return fmt.Errorf("ratelimit: requested %d tokens exceeds burst capacity %.0f", n, r.bucketSize)
+	}
+
+	r.refillTokens()
+
+	// Fast path: nobody is queued and enough tokens are available
+	if r.queued() == 0 && r.tokens >= tokens {
+		r.tokens -= tokens
+		r.recordGrant(PriorityFromContext(ctx), tokens)
+		r.mu.Unlock()
+		return nil
+	}
 
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(waitTime):
-			// Try again
+	priority := PriorityFromContext(ctx)
+	w := &waiter{n: tokens, ready: make(chan struct{})}
+	r.lanes[priority] = append(r.lanes[priority], w)
+	r.dispatch()
+	r.mu.Unlock()
+
+	select {
+	case <-w.ready:
+		return nil
+	case <-ctx.Done():
+		r.mu.Lock()
+		if w.granted {
+			// Granted concurrently with cancellation: give the tokens back
+			r.tokens = min(r.tokens+w.n, r.bucketSize)
+		} else {
+			r.removeWaiter(priority, w)
 		}
+		r.dispatch()
+		r.mu.Unlock()
+		return ctx.Err()
 	}
 }
 
 // TryAcquire attempts to acquire a token without blocking.
 // Returns true if a token was acquired, false otherwise.
+// It never takes a token ahead of queued waiters.
 func (r *RateLimiter) TryAcquire() bool {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
 	r.refillTokens()
 
-	if r.tokens >= 1.0 {
+	if r.queued() == 0 && r.tokens >= 1.0 {
 		r.tokens -= 1.0
 		return true
 	}
@@ -67,6 +129,219 @@ func (r *RateLimiter) TryAcquire() bool {
 	return false
 }
 
+// Reservation holds tokens taken from a RateLimiter ahead of time.
+type Reservation struct {
+	limiter   *RateLimiter
+	tokens    float64
+	timeToAct time.Time
+	ok        bool
+	cancelled bool
+}
+
+// Reserve takes n tokens immediately and returns a Reservation describing
+// how long the caller must wait before acting. Reservations do not queue
+// behind waiters, so they bypass priority ordering.
+// The reservation is not OK if n is less than 1 or exceeds the burst capacity.
+func (r *RateLimiter) Reserve(n int) *Reservation {
+	tokens := float64(n)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if n < 1 || tokens > r.bucketSize {
+		return &Reservation{limiter: r}
+	}
+
+	r.refillTokens()
+	r.tokens -= tokens
+
+	var delay time.Duration
+	if r.tokens < 0 {
+		delay = r.durationFor(-r.tokens)
+	}
+
+	return &Reservation{
+		limiter:   r,
+		tokens:    tokens,
+		timeToAct: time.Now().Add(delay),
+		ok:        true,
+	}
+}
+
+// OK reports whether the reservation could be made.
+// It is false if fewer than 1 or more tokens than the burst capacity were
+// requested.
+func (res *Reservation) OK() bool {
+	return res.ok
+}
+
+// Delay returns how long the caller must wait before acting on the reservation.
+func (res *Reservation) Delay() time.Duration {
+	if !res.ok {
+		return 0
+	}
+	return max(time.Until(res.timeToAct), 0)
+}
+
+// Cancel returns the reserved tokens to the limiter if the reservation has
+// not yet become due.
+func (res *Reservation) Cancel() {
+	if !res.ok {
+		return
+	}
+
+	r := res.limiter
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if res.cancelled || !time.Now().Before(res.timeToAct) {
+		return
+	}
+	res.cancelled = true
+
+	r.refillTokens()
+	r.tokens = min(r.tokens+res.tokens, r.bucketSize)
+	r.dispatch()
+}
+
+// SetRate changes the rate limit at runtime.
+func (r *RateLimiter) SetRate(requestsPerSecond float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	// Account for tokens earned at the old rate first
+	r.refillTokens()
+	r.rate = requestsPerSecond
+	r.dispatch()
+}
+
+// SetBurst changes the burst capacity at runtime.
+func (r *RateLimiter) SetBurst(burstCapacity float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.refillTokens()
+	r.bucketSize = burstCapacity
+	if r.tokens > r.bucketSize {
+		r.tokens = r.bucketSize
+	}
+	r.dispatch()
+}
+
+// SetMinShare guarantees the given priority lane at least share (0-1) of the
+// granted tokens while it has waiters, even if higher-priority lanes are busy.
+func (r *RateLimiter) SetMinShare(p Priority, share float64) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.minShare[p.clamp()] = min(max(share, 0), 1)
+	r.dispatch()
+}
+
+// dispatch grants tokens to queued waiters in priority order and arms the
+// timer for the next waiter that cannot be served yet.
+// Must be called with mutex held.
+func (r *RateLimiter) dispatch() {
+	if r.timer != nil {
+		r.timer.Stop()
+		r.timer = nil
+	}
+
+	r.refillTokens()
+
+	for {
+		lane := r.nextLane()
+		if lane < 0 {
+			return
+		}
+
+		w := r.lanes[lane][0]
+		if r.tokens < w.n {
+			if r.rate <= 0 {
+				return
+			}
+			r.timer = time.AfterFunc(r.durationFor(w.n-r.tokens), func() {
+				r.mu.Lock()
+				r.dispatch()
+				r.mu.Unlock()
+			})
+			return
+		}
+
+		r.tokens -= w.n
+		r.lanes[lane] = r.lanes[lane][1:]
+		r.recordGrant(Priority(lane), w.n)
+		w.granted = true
+		close(w.ready)
+	}
+}
+
+// nextLane returns the lane whose head waiter should be served next,
+// or -1 if nobody is waiting. Lanes below their minimum share are served
+// first; otherwise the highest priority lane wins.
+// Must be called with mutex held.
+func (r *RateLimiter) nextLane() int {
+	var total float64
+	for _, s := range r.served {
+		total += s
+	}
+
+	best, bestDeficit := -1, 0.0
+	for lane := range r.lanes {
+		if len(r.lanes[lane]) == 0 || r.minShare[lane] == 0 {
+			continue
+		}
+		share := 0.0
+		if total > 0 {
+			share = r.served[lane] / total
+		}
+		if deficit := r.minShare[lane] - share; deficit > bestDeficit {
+			best, bestDeficit = lane, deficit
+		}
+	}
+	if best >= 0 {
+		return best
+	}
+
+	for lane := numPriorities - 1; lane >= 0; lane-- {
+		if len(r.lanes[lane]) > 0 {
+			return lane
+		}
+	}
+	return -1
+}
+
+// recordGrant updates the decayed per-lane grant counts.
+// Must be called with mutex held.
+func (r *RateLimiter) recordGrant(p Priority, tokens float64) {
+	for i := range r.served {
+		r.served[i] *= shareDecay
+	}
+	r.served[p.clamp()] += tokens
+}
+
+// removeWaiter removes w from its lane.
+// Must be called with mutex held.
+func (r *RateLimiter) removeWaiter(p Priority, w *waiter) {
+	lane := r.lanes[p]
+	for i, other := range lane {
+		if other == w {
+			r.lanes[p] = append(lane[:i:i], lane[i+1:]...)
+			return
+		}
+	}
+}
+
+// queued returns the number of waiters across all lanes.
+// Must be called with mutex held.
+func (r *RateLimiter) queued() int {
+	n := 0
+	for _, lane := range r.lanes {
+		n += len(lane)
+	}
+	return n
+}
+
 // refillTokens adds tokens based on elapsed time since last update.
 // Must be called with mutex held.
 func (r *RateLimiter) refillTokens() {
@@ -83,19 +358,13 @@ func (r *RateLimiter) refillTokens() {
 	}
 }
 
-// timeUntilNextToken returns the duration until the next token is available.
-func (r *RateLimiter) timeUntilNextToken() time.Duration {
-	r.mu.Lock()
-	defer r.mu.Unlock()
-
-	if r.tokens >= 1.0 {
+// durationFor returns the time needed to accumulate the given number of tokens.
+// Must be called with mutex held.
+func (r *RateLimiter) durationFor(tokens float64) time.Duration {
+	if r.rate <= 0 {
 		return 0
 	}
-
-	// Calculate time needed to accumulate 1 token
-	tokensNeeded := 1.0 - r.tokens
-	secondsNeeded := tokensNeeded / r.rate
-
+	secondsNeeded := tokens / r.rate
 	return time.Duration(secondsNeeded * float64(time.Second))
 }
 
@@ -108,7 +377,18 @@ func (r *RateLimiter) Available() float64 {
 	return r.tokens
 }
 
+// Waiting returns the number of callers queued in the given priority lane.
+func (r *RateLimiter) Waiting(p Priority) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return len(r.lanes[p.clamp()])
+}
+
 // Rate returns the rate limit in requests per second.
 func (r *RateLimiter) Rate() float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
 	return r.rate
 }
@@ -0,0 +1,204 @@
+package middleware
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+)
+
+// newStalledLimiter returns a limiter with no tokens that never refills on
+// its own, so tests decide when tokens arrive with grant.
+func newStalledLimiter() *RateLimiter {
+	r := NewRateLimiterWithBurst(1, 1)
+	r.tokens = 0
+	r.SetRate(0)
+	return r
+}
+
+// grant adds n tokens to r and serves queued waiters.
+func grant(r *RateLimiter, n float64) {
+	r.mu.Lock()
+	r.tokens += n
+	r.dispatch()
+	r.mu.Unlock()
+}
+
+// enqueue starts a waiter at priority p that sends name to served once it
+// is granted a token, and returns when the waiter is queued.
+func enqueue(t *testing.T, r *RateLimiter, p Priority, name string, served chan<- string) {
+	t.Helper()
+
+	before := r.Waiting(p)
+	go func() {
+		if err := r.Wait(WithPriority(context.Background(), p)); err != nil {
+			t.Errorf("Wait(%s): %v", name, err)
+			return
+		}
+		served <- name
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for r.Waiting(p) == before {
+		if time.Now().After(deadline) {
+			t.Fatalf("waiter %s was not queued", name)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// serveAll grants one token at a time and returns the order waiters were served in.
+func serveAll(t *testing.T, r *RateLimiter, n int, served <-chan string) []string {
+	t.Helper()
+
+	var order []string
+	for range n {
+		grant(r, 1)
+		select {
+		case name := <-served:
+			order = append(order, name)
+		case <-time.After(time.Second):
+			t.Fatalf("no waiter served after %v", order)
+		}
+	}
+	return order
+}
+
+func TestRateLimiterPriorityOrder(t *testing.T) {
+	r := newStalledLimiter()
+	served := make(chan string)
+
+	enqueue(t, r, PriorityLow, "low1", served)
+	enqueue(t, r, PriorityNormal, "normal1", served)
+	enqueue(t, r, PriorityHigh, "high1", served)
+	enqueue(t, r, PriorityLow, "low2", served)
+	enqueue(t, r, PriorityHigh, "high2", served)
+
+	got := serveAll(t, r, 5, served)
+	want := []string{"high1", "high2", "normal1", "low1", "low2"}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("served %v, want %v", got, want)
+		}
+	}
+}
+
+func TestRateLimiterMinShare(t *testing.T) {
+	tests := []struct {
+		name     string
+		minShare float64
+		want     []string
+	}{
+		{
+			name: "without share",
+			want: []string{"high", "high", "high", "high", "high", "high", "low", "low"},
+		},
+		{
+			name:     "with share",
+			minShare: 0.25,
+			want:     []string{"low", "high", "high", "high", "low", "high", "high", "high"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := newStalledLimiter()
+			r.SetMinShare(PriorityLow, tt.minShare)
+			served := make(chan string)
+
+			for range 2 {
+				enqueue(t, r, PriorityLow, "low", served)
+			}
+			for range 6 {
+				enqueue(t, r, PriorityHigh, "high", served)
+			}
+
+			got := serveAll(t, r, len(tt.want), served)
+			for i := range tt.want {
+				if got[i] != tt.want[i] {
+					t.Fatalf("served %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReservationCancel(t *testing.T) {
+	// At 0.001 tokens per second the bucket does not noticeably refill during the test
+	r := NewRateLimiterWithBurst(0.001, 2)
+
+	due := r.Reserve(2)
+	if !due.OK() || due.Delay() != 0 {
+		t.Fatalf("Reserve(2) on a full bucket: ok %v, delay %v; want ok with no delay", due.OK(), due.Delay())
+	}
+
+	pending := r.Reserve(2)
+	if !pending.OK() || pending.Delay() < time.Minute {
+		t.Fatalf("Reserve(2) on an empty bucket: ok %v, delay %v; want ok with a long delay", pending.OK(), pending.Delay())
+	}
+	if got := r.Available(); math.Abs(got+2) > 0.01 {
+		t.Fatalf("Available() = %v with a pending reservation, want -2", got)
+	}
+
+	pending.Cancel()
+	if got := r.Available(); math.Abs(got) > 0.01 {
+		t.Fatalf("Available() = %v after Cancel, want 0", got)
+	}
+
+	// Cancelling twice, or cancelling a reservation that is already due, refunds nothing
+	pending.Cancel()
+	due.Cancel()
+	if got := r.Available(); math.Abs(got) > 0.01 {
+		t.Fatalf("Available() = %v after repeated Cancel, want 0", got)
+	}
+
+	if res := r.Reserve(3); res.OK() {
+		t.Fatal("Reserve(3) beyond the burst capacity succeeded")
+	}
+}
+
+func TestRateLimiterRejectsTokenCountsBelowOne(t *testing.T) {
+	r := NewRateLimiterWithBurst(0.001, 2)
+
+	for _, n := range []int{0, -1} {
+		if err := r.WaitN(context.Background(), n); err == nil {
+			t.Fatalf("WaitN(%d) succeeded", n)
+		}
+		if res := r.Reserve(n); res.OK() {
+			t.Fatalf("Reserve(%d) succeeded", n)
+		}
+	}
+
+	// Refunds never fill the bucket past its burst capacity
+	r.Reserve(2)
+	pending := r.Reserve(2)
+	grant(r, 4)
+	pending.Cancel()
+	if got := r.Available(); math.Abs(got-2) > 0.01 {
+		t.Fatalf("Available() = %v after Cancel on a full bucket, want the burst capacity 2", got)
+	}
+}
+
+func TestReservationCancelServesWaiters(t *testing.T) {
+	r := NewRateLimiterWithBurst(0.001, 1)
+	served := make(chan string)
+
+	r.Reserve(1)
+	pending := r.Reserve(1)
+
+	enqueue(t, r, PriorityNormal, "waiter", served)
+	grant(r, 1) // pays back the pending reservation's debt only
+
+	select {
+	case name := <-served:
+		t.Fatalf("%s served before the reservation was cancelled", name)
+	case <-time.After(10 * time.Millisecond):
+	}
+
+	pending.Cancel()
+	select {
+	case <-served:
+	case <-time.After(time.Second):
+		t.Fatal("waiter not served after the reservation was cancelled")
+	}
+}
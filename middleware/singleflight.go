@@ -0,0 +1,63 @@
+package middleware
+
+import "sync"
+
+// call is an in-flight or completed Group.Do call.
+type call struct {
+	wg    sync.WaitGroup
+	value interface{}
+	err   error
+	dups  int
+}
+
+// Group deduplicates concurrent calls that share a key.
+// The zero value is ready to use.
+type Group struct {
+	mu    sync.Mutex
+	calls map[string]*call
+}
+
+// Do executes fn for the given key, making sure only one execution is in
+// flight at a time. Duplicate callers wait for the original call and receive
+// the same result. shared reports whether the result was given to more than
+// one caller.
+func (g *Group) Do(key string, fn func() (interface{}, error)) (value interface{}, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*call)
+	}
+	if c, ok := g.calls[key]; ok {
+		c.dups++
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.value, c.err, true
+	}
+
+	c := &call{}
+	c.wg.Add(1)
+	g.calls[key] = c
+	g.mu.Unlock()
+
+	defer func() {
+		g.mu.Lock()
+		delete(g.calls, key)
+		g.mu.Unlock()
+		c.wg.Done()
+	}()
+
+	c.value, c.err = fn()
+
+	g.mu.Lock()
+	shared = c.dups > 0
+	g.mu.Unlock()
+
+	return c.value, c.err, shared
+}
+
+// InFlight reports whether a call for the given key is currently running.
+func (g *Group) InFlight(key string) bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.calls[key]
+	return ok
+}
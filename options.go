@@ -0,0 +1,107 @@
+package tradera
+
+import (
+	"context"
+	"time"
+
+	"github.com/SebbeJohansson/tradera-go-client/middleware"
+)
+
+// CallOption overrides a client setting for a single call. Options are passed
+// as trailing arguments to any client method, or attached to a context with
+// WithCallOptions; arguments take precedence over the context.
+type CallOption func(*callOptions)
+
+// callOptions holds the resolved per-call overrides.
+type callOptions struct {
+	noCache     bool
+	retries     int // -1 = client default
+	timeout     time.Duration
+	priority    middleware.Priority
+	hasPriority bool
+	user        *Credentials
+}
+
+// WithNoCache skips the cache lookup for the call. The fresh result still
+// replaces the cached one, so later calls see it.
+func WithNoCache() CallOption {
+	return func(o *callOptions) {
+		o.noCache = true
+	}
+}
+
+// WithRetry sets the maximum number of retries for the call, using the
+// client's backoff settings. Zero disables retry.
+func WithRetry(maxRetries int) CallOption {
+	return func(o *callOptions) {
+		o.retries = max(maxRetries, 0)
+	}
+}
+
+// WithTimeout bounds the whole call, including rate limiting and retries.
+func WithTimeout(timeout time.Duration) CallOption {
+	return func(o *callOptions) {
+		o.timeout = timeout
+	}
+}
+
+// WithPriority sets the rate limiter and quota priority of the call.
+func WithPriority(priority middleware.Priority) CallOption {
+	return func(o *callOptions) {
+		o.priority = priority
+		o.hasPriority = true
+	}
+}
+
+// WithUser makes the call on behalf of the given user instead of the one in Config.
+// Calls requiring user auth fail with ErrAuthRequired if userID or token is unset.
+func WithUser(userID int, token string) CallOption {
+	return WithUserCredentials(Credentials{UserID: userID, Token: token})
+}
+
+// WithUserCredentials is like WithUser, but also checks the token's expiry:
+// an expired token fails the call with ErrTokenExpired, and one about to
+// expire triggers Config.OnTokenExpiring.
+func WithUserCredentials(creds Credentials) CallOption {
+	return func(o *callOptions) {
+		o.user = &creds
+	}
+}
+
+type callOptionsKey struct{}
+
+// WithCallOptions returns a context carrying opts, which then apply to every
+// call made with it. Options already in ctx are kept; opts take precedence.
+func WithCallOptions(ctx context.Context, opts ...CallOption) context.Context {
+	inherited, _ := ctx.Value(callOptionsKey{}).([]CallOption)
+	combined := make([]CallOption, 0, len(inherited)+len(opts))
+	combined = append(combined, inherited...)
+	combined = append(combined, opts...)
+	return context.WithValue(ctx, callOptionsKey{}, combined)
+}
+
+// resolveCallOptions applies the options in ctx, then opts.
+func resolveCallOptions(ctx context.Context, opts []CallOption) callOptions {
+	options := callOptions{retries: -1}
+	inherited, _ := ctx.Value(callOptionsKey{}).([]CallOption)
+	for _, opt := range inherited {
+		opt(&options)
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	return options
+}
+
+type authKey struct{}
+
+// withAuth returns a context whose calls use auth for user authorization.
+func withAuth(ctx context.Context, auth *AuthorizationHeader) context.Context {
+	return context.WithValue(ctx, authKey{}, auth)
+}
+
+// authFromContext returns the per-call user authorization, or nil to use the client's.
+func authFromContext(ctx context.Context) *AuthorizationHeader {
+	auth, _ := ctx.Value(authKey{}).(*AuthorizationHeader)
+	return auth
+}
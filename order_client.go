@@ -7,20 +7,33 @@ import (
 )
 
 // OrderClient provides access to the Tradera Order API.
-// Requires user authentication (UserID and Token in config).
+// Requires user authentication (UserID and Token in config, a CredentialProvider, or WithUser per call).
 type OrderClient struct {
 	client  *Client
 	service order.OrderServiceSoap
 }
 
 func newOrderClient(c *Client) *OrderClient {
-	soapClient := c.createSOAPClient(OrderServiceURL)
+	soapClient := c.createSOAPClient(OrderServiceURL, c.configAuth())
 	return &OrderClient{
 		client:  c,
 		service: order.NewOrderServiceSoap(soapClient),
 	}
 }
 
+// op returns the operation descriptor for an action of this service.
+func (c *OrderClient) op(action string, request any, opts []CallOption) Call {
+	return Call{Service: ServiceOrder, Action: action, Request: request, opts: opts, userAuth: true}
+}
+
+// serviceFor returns the service to call, honoring a per-call user from WithUser.
+func (c *OrderClient) serviceFor(ctx context.Context) order.OrderServiceSoap {
+	if auth := authFromContext(ctx); auth != nil {
+		return order.NewOrderServiceSoap(c.client.createSOAPClient(OrderServiceURL, auth))
+	}
+	return c.service
+}
+
 // SellerOrder represents an order for a seller.
 type SellerOrder struct {
 	ID             int32
@@ -35,13 +48,11 @@ type SellerOrder struct {
 }
 
 // GetSellerOrders retrieves orders for the authenticated seller.
-func (c *OrderClient) GetSellerOrders(ctx context.Context) ([]*SellerOrder, error) {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return nil, err
-	}
+func (c *OrderClient) GetSellerOrders(ctx context.Context, opts ...CallOption) ([]*SellerOrder, error) {
+	request := &order.GetSellerOrders{}
 
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*order.GetSellerOrdersResponse, error) {
-		return c.service.GetSellerOrdersContext(ctx, &order.GetSellerOrders{})
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetSellerOrders", request, opts), func(ctx context.Context) (*order.GetSellerOrdersResponse, error) {
+		return c.serviceFor(ctx).GetSellerOrdersContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -66,33 +77,29 @@ func (c *OrderClient) GetSellerOrders(ctx context.Context) ([]*SellerOrder, erro
 }
 
 // SetSellerOrderAsShipped marks an order as shipped.
-func (c *OrderClient) SetSellerOrderAsShipped(ctx context.Context, orderID int32) error {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return err
+func (c *OrderClient) SetSellerOrderAsShipped(ctx context.Context, orderID int32, opts ...CallOption) error {
+	request := &order.SetSellerOrderAsShipped{
+		Request: &order.SetSellerOrderAsShippedRequest{
+			OrderId: orderID,
+		},
 	}
 
-	return c.client.executeWithMiddleware(ctx, func() error {
-		_, err := c.service.SetSellerOrderAsShippedContext(ctx, &order.SetSellerOrderAsShipped{
-			Request: &order.SetSellerOrderAsShippedRequest{
-				OrderId: orderID,
-			},
-		})
+	return c.client.executeWithMiddleware(ctx, c.op("SetSellerOrderAsShipped", request, opts).withOrder(orderID), func(ctx context.Context) error {
+		_, err := c.serviceFor(ctx).SetSellerOrderAsShippedContext(ctx, request)
 		return err
 	})
 }
 
 // SetSellerOrderAsPaid marks an order as paid.
-func (c *OrderClient) SetSellerOrderAsPaid(ctx context.Context, orderID int32) error {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return err
+func (c *OrderClient) SetSellerOrderAsPaid(ctx context.Context, orderID int32, opts ...CallOption) error {
+	request := &order.SetSellerOrderAsPaid{
+		Request: &order.SetSellerOrderAsPaidRequest{
+			OrderId: orderID,
+		},
 	}
 
-	return c.client.executeWithMiddleware(ctx, func() error {
-		_, err := c.service.SetSellerOrderAsPaidContext(ctx, &order.SetSellerOrderAsPaid{
-			Request: &order.SetSellerOrderAsPaidRequest{
-				OrderId: orderID,
-			},
-		})
+	return c.client.executeWithMiddleware(ctx, c.op("SetSellerOrderAsPaid", request, opts).withOrder(orderID), func(ctx context.Context) error {
+		_, err := c.serviceFor(ctx).SetSellerOrderAsPaidContext(ctx, request)
 		return err
 	})
 }
@@ -2,11 +2,20 @@ package tradera
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/SebbeJohansson/tradera-go-client/generated/public"
+	"github.com/SebbeJohansson/tradera-go-client/middleware"
 )
 
+func init() {
+	// Allow cached results to be stored in a middleware.DiskCache
+	middleware.RegisterCacheType((*CategoryTree)(nil))
+	middleware.RegisterCacheType((*Item)(nil))
+	middleware.RegisterCacheType((*SearchResult)(nil))
+}
+
 // PublicClient provides access to the Tradera Public API.
 type PublicClient struct {
 	client  *Client
@@ -14,13 +23,26 @@ type PublicClient struct {
 }
 
 func newPublicClient(c *Client) *PublicClient {
-	soapClient := c.createSOAPClient(PublicServiceURL)
+	soapClient := c.createSOAPClient(PublicServiceURL, c.configAuth())
 	return &PublicClient{
 		client:  c,
 		service: public.NewPublicServiceSoap(soapClient),
 	}
 }
 
+// op returns the operation descriptor for an action of this service.
+func (c *PublicClient) op(action string, request any, opts []CallOption) Call {
+	return Call{Service: ServicePublic, Action: action, Request: request, opts: opts}
+}
+
+// serviceFor returns the service to call, honoring a per-call user from WithUser.
+func (c *PublicClient) serviceFor(ctx context.Context) public.PublicServiceSoap {
+	if auth := authFromContext(ctx); auth != nil {
+		return public.NewPublicServiceSoap(c.client.createSOAPClient(PublicServiceURL, auth))
+	}
+	return c.service
+}
+
 // Item represents a Tradera item with full details.
 type Item struct {
 	ID                int32
@@ -95,28 +117,36 @@ type Category struct {
 	ID       int32
 	Name     string
 	Children []*Category
+
+	parent *Category // set by NewCategoryTree
 }
 
 // GetItem retrieves detailed information about a specific item.
-func (c *PublicClient) GetItem(ctx context.Context, itemID int32) (*Item, error) {
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*public.GetItemResponse, error) {
-		return c.service.GetItemContext(ctx, &public.GetItem{
-			ItemId: itemID,
-		})
-	})
-	if err != nil {
-		return nil, err
+// Results are cached when caching is enabled.
+func (c *PublicClient) GetItem(ctx context.Context, itemID int32, opts ...CallOption) (*Item, error) {
+	request := &public.GetItem{
+		ItemId: itemID,
 	}
 
-	return convertPublicItem(result.GetItemResult), nil
+	op := c.op("GetItem", request, opts).withItem(itemID).withCache(fmt.Sprintf("item:%d", itemID))
+	return executeWithMiddlewareResult(c.client, ctx, op, func(ctx context.Context) (*Item, error) {
+		result, err := c.serviceFor(ctx).GetItemContext(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		return convertPublicItem(result.GetItemResult), nil
+	})
 }
 
 // GetUserByAlias retrieves a user by their alias.
-func (c *PublicClient) GetUserByAlias(ctx context.Context, alias string) (*User, error) {
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*public.GetUserByAliasResponse, error) {
-		return c.service.GetUserByAliasContext(ctx, &public.GetUserByAlias{
-			Alias: alias,
-		})
+func (c *PublicClient) GetUserByAlias(ctx context.Context, alias string, opts ...CallOption) (*User, error) {
+	request := &public.GetUserByAlias{
+		Alias: alias,
+	}
+
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetUserByAlias", request, opts), func(ctx context.Context) (*public.GetUserByAliasResponse, error) {
+		return c.serviceFor(ctx).GetUserByAliasContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -127,12 +157,14 @@ func (c *PublicClient) GetUserByAlias(ctx context.Context, alias string) (*User,
 
 // FetchToken retrieves an authorization token for a user.
 // This token is required for authenticated operations.
-func (c *PublicClient) FetchToken(ctx context.Context, userID int32, secretKey string) (string, error) {
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*public.FetchTokenResponse, error) {
-		return c.service.FetchTokenContext(ctx, &public.FetchToken{
-			UserId:    userID,
-			SecretKey: secretKey,
-		})
+func (c *PublicClient) FetchToken(ctx context.Context, userID int32, secretKey string, opts ...CallOption) (string, error) {
+	request := &public.FetchToken{
+		UserId:    userID,
+		SecretKey: secretKey,
+	}
+
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("FetchToken", request, opts).withUser(userID), func(ctx context.Context) (*public.FetchTokenResponse, error) {
+		return c.serviceFor(ctx).FetchTokenContext(ctx, request)
 	})
 	if err != nil {
 		return "", err
@@ -142,9 +174,11 @@ func (c *PublicClient) FetchToken(ctx context.Context, userID int32, secretKey s
 }
 
 // GetOfficialTime retrieves the official Tradera server time.
-func (c *PublicClient) GetOfficialTime(ctx context.Context) (time.Time, error) {
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*public.GetOfficalTimeResponse, error) {
-		return c.service.GetOfficalTimeContext(ctx, &public.GetOfficalTime{})
+func (c *PublicClient) GetOfficialTime(ctx context.Context, opts ...CallOption) (time.Time, error) {
+	request := &public.GetOfficalTime{}
+
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetOfficalTime", request, opts), func(ctx context.Context) (*public.GetOfficalTimeResponse, error) {
+		return c.serviceFor(ctx).GetOfficalTimeContext(ctx, request)
 	})
 	if err != nil {
 		return time.Time{}, err
@@ -154,38 +188,30 @@ func (c *PublicClient) GetOfficialTime(ctx context.Context) (time.Time, error) {
 }
 
 // GetCategories retrieves the full category tree.
-func (c *PublicClient) GetCategories(ctx context.Context) ([]*Category, error) {
-	// Check cache first
-	if c.client.cache != nil {
-		if cached, ok := c.client.cache.Get("categories"); ok {
-			return cached.([]*Category), nil
+// Results are cached when caching is enabled.
+func (c *PublicClient) GetCategories(ctx context.Context, opts ...CallOption) (*CategoryTree, error) {
+	request := &public.GetCategories{}
+
+	op := c.op("GetCategories", request, opts).withCache("category-tree")
+	return executeWithMiddlewareResult(c.client, ctx, op, func(ctx context.Context) (*CategoryTree, error) {
+		result, err := c.serviceFor(ctx).GetCategoriesContext(ctx, request)
+		if err != nil {
+			return nil, err
 		}
-	}
 
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*public.GetCategoriesResponse, error) {
-		return c.service.GetCategoriesContext(ctx, &public.GetCategories{})
+		return NewCategoryTree(convertCategories(result.GetCategoriesResult)), nil
 	})
-	if err != nil {
-		return nil, err
-	}
-
-	categories := convertCategories(result.GetCategoriesResult)
-
-	// Cache the result
-	if c.client.cache != nil {
-		c.client.cache.Set("categories", categories)
-	}
-
-	return categories, nil
 }
 
 // GetSellerItems retrieves items for a specific seller.
-func (c *PublicClient) GetSellerItems(ctx context.Context, userID int32, categoryID int32) ([]*Item, error) {
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*public.GetSellerItemsResponse, error) {
-		return c.service.GetSellerItemsContext(ctx, &public.GetSellerItems{
-			UserId:     userID,
-			CategoryId: categoryID,
-		})
+func (c *PublicClient) GetSellerItems(ctx context.Context, userID int32, categoryID int32, opts ...CallOption) ([]*Item, error) {
+	request := &public.GetSellerItems{
+		UserId:     userID,
+		CategoryId: categoryID,
+	}
+
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetSellerItems", request, opts).withUser(userID), func(ctx context.Context) (*public.GetSellerItemsResponse, error) {
+		return c.serviceFor(ctx).GetSellerItemsContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -195,9 +221,11 @@ func (c *PublicClient) GetSellerItems(ctx context.Context, userID int32, categor
 }
 
 // GetCounties retrieves the list of Swedish counties.
-func (c *PublicClient) GetCounties(ctx context.Context) ([]*IdDescriptionPair, error) {
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*public.GetCountiesResponse, error) {
-		return c.service.GetCountiesContext(ctx, &public.GetCounties{})
+func (c *PublicClient) GetCounties(ctx context.Context, opts ...CallOption) ([]*IdDescriptionPair, error) {
+	request := &public.GetCounties{}
+
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetCounties", request, opts), func(ctx context.Context) (*public.GetCountiesResponse, error) {
+		return c.serviceFor(ctx).GetCountiesContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -392,11 +420,13 @@ type PublicSearchResult struct {
 // GetSearchResultAdvanced performs an advanced search using the Public service.
 // Returns full Item objects with Status, Seller, and other detailed fields.
 // This is useful for searching ended/sold items for price tracking.
-func (c *PublicClient) GetSearchResultAdvanced(ctx context.Context, query *public.Query) (*PublicSearchResult, error) {
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*public.GetSearchResultAdvancedResponse, error) {
-		return c.service.GetSearchResultAdvancedContext(ctx, &public.GetSearchResultAdvanced{
-			Query: query,
-		})
+func (c *PublicClient) GetSearchResultAdvanced(ctx context.Context, query *public.Query, opts ...CallOption) (*PublicSearchResult, error) {
+	request := &public.GetSearchResultAdvanced{
+		Query: query,
+	}
+
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetSearchResultAdvanced", request, opts), func(ctx context.Context) (*public.GetSearchResultAdvancedResponse, error) {
+		return c.serviceFor(ctx).GetSearchResultAdvancedContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,490 @@
+package tradera
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Query describes a search and builds the request for SearchAdvanced or
+// SearchCategoryCount, so a result list and its category counts can share
+// one definition. The zero Query matches everything; each method returns a
+// modified copy:
+//
+//	q := tradera.NewQuery().Words("leica").InCategory(16).Price(500, 5000)
+//	req, err := q.AdvancedRequest()
+//
+// A Query can be encoded to a URL query string with Encode and restored with
+// ParseQuery, to share and bookmark searches.
+type Query struct {
+	words         string
+	mode          SearchMode
+	inDescription bool
+	categoryID    int32
+	priceMin      *int32
+	priceMax      *int32
+	bidsMin       *int32
+	bidsMax       *int32
+	zipCode       string
+	countyID      int32
+	seller        string
+	sellerType    SearchSellerType
+	brands        []string
+	condition     SearchItemCondition
+	itemType      SearchItemType
+	status        SearchItemStatus
+	orderBy       SearchOrderBy
+	buyNowOnly    bool
+	thumbnailOnly bool
+	perPage       int32
+	page          int32
+}
+
+// NewQuery returns an empty query.
+func NewQuery() Query {
+	return Query{}
+}
+
+// Words sets the search words, joined by spaces.
+func (q Query) Words(words ...string) Query {
+	q.words = strings.Join(words, " ")
+	return q
+}
+
+// Mode sets how multiple words are matched (default: all words).
+func (q Query) Mode(mode SearchMode) Query {
+	q.mode = mode
+	return q
+}
+
+// InDescription also matches the words against item descriptions.
+func (q Query) InDescription() Query {
+	q.inDescription = true
+	return q
+}
+
+// InCategory limits the search to a category and its subcategories.
+func (q Query) InCategory(categoryID int32) Query {
+	q.categoryID = categoryID
+	return q
+}
+
+// Price limits the current price to min..max SEK, inclusive.
+func (q Query) Price(min, max int32) Query {
+	return q.MinPrice(min).MaxPrice(max)
+}
+
+// MinPrice sets the lowest price in SEK.
+func (q Query) MinPrice(min int32) Query {
+	q.priceMin = &min
+	return q
+}
+
+// MaxPrice sets the highest price in SEK.
+func (q Query) MaxPrice(max int32) Query {
+	q.priceMax = &max
+	return q
+}
+
+// Bids limits the number of bids to min..max, inclusive.
+// Bids(0, 0) matches items without bids.
+func (q Query) Bids(min, max int32) Query {
+	return q.MinBids(min).MaxBids(max)
+}
+
+// MinBids sets the lowest number of bids.
+func (q Query) MinBids(min int32) Query {
+	q.bidsMin = &min
+	return q
+}
+
+// MaxBids sets the highest number of bids.
+func (q Query) MaxBids(max int32) Query {
+	q.bidsMax = &max
+	return q
+}
+
+// ZipCode limits the search to items located near a zip code.
+func (q Query) ZipCode(zipCode string) Query {
+	q.zipCode = zipCode
+	return q
+}
+
+// County limits the search to items located in a county.
+func (q Query) County(countyID int32) Query {
+	q.countyID = countyID
+	return q
+}
+
+// Seller limits the search to items sold by the member with alias.
+func (q Query) Seller(alias string) Query {
+	q.seller = alias
+	return q
+}
+
+// SellerType limits the search to private or business sellers.
+func (q Query) SellerType(sellerType SearchSellerType) Query {
+	q.sellerType = sellerType
+	return q
+}
+
+// Brands adds brands to limit the search to.
+func (q Query) Brands(brands ...string) Query {
+	q.brands = append(slices.Clip(q.brands), brands...)
+	return q
+}
+
+// Condition limits the search to new or second hand items.
+func (q Query) Condition(condition SearchItemCondition) Query {
+	q.condition = condition
+	return q
+}
+
+// Type limits the search to auctions or fixed price items.
+func (q Query) Type(itemType SearchItemType) Query {
+	q.itemType = itemType
+	return q
+}
+
+// Status selects active or ended items (default: active).
+func (q Query) Status(status SearchItemStatus) Query {
+	q.status = status
+	return q
+}
+
+// OrderBy sets the sort order of the results.
+func (q Query) OrderBy(orderBy SearchOrderBy) Query {
+	q.orderBy = orderBy
+	return q
+}
+
+// OnlyWithBuyNow limits the search to auctions that can also be bought now.
+func (q Query) OnlyWithBuyNow() Query {
+	q.buyNowOnly = true
+	return q
+}
+
+// OnlyWithThumbnail limits the search to items with a thumbnail image.
+func (q Query) OnlyWithThumbnail() Query {
+	q.thumbnailOnly = true
+	return q
+}
+
+// PerPage sets the number of items per result page.
+func (q Query) PerPage(n int32) Query {
+	q.perPage = n
+	return q
+}
+
+// Page sets the result page to fetch, starting at 1.
+func (q Query) Page(page int32) Query {
+	q.page = page
+	return q
+}
+
+// Validate reports invalid values and conflicting filters, such as a price
+// range whose minimum exceeds its maximum. Errors match ErrInvalidParameter.
+func (q Query) Validate() error {
+	var errs []error
+	conflict := func(msg string) {
+		errs = append(errs, fmt.Errorf("%w: %s", ErrInvalidParameter, msg))
+	}
+
+	errs = append(errs,
+		checkEnum("Mode", searchModeValues, q.mode),
+		checkEnum("OrderBy", searchOrderByValues, q.orderBy),
+		checkEnum("ItemStatus", searchItemStatusValues, q.status),
+		checkEnum("ItemType", searchItemTypeValues, q.itemType),
+		checkEnum("ItemCondition", searchItemConditionValues, q.condition),
+		checkEnum("SellerType", searchSellerTypeValues, q.sellerType),
+	)
+
+	if (q.priceMin != nil && *q.priceMin < 0) || (q.priceMax != nil && *q.priceMax < 0) {
+		conflict("negative price")
+	}
+	if q.priceMin != nil && q.priceMax != nil && *q.priceMin > *q.priceMax {
+		conflict(fmt.Sprintf("minimum price %d above maximum %d", *q.priceMin, *q.priceMax))
+	}
+	if (q.bidsMin != nil && *q.bidsMin < 0) || (q.bidsMax != nil && *q.bidsMax < 0) {
+		conflict("negative number of bids")
+	}
+	if q.bidsMin != nil && q.bidsMax != nil && *q.bidsMin > *q.bidsMax {
+		conflict(fmt.Sprintf("minimum bids %d above maximum %d", *q.bidsMin, *q.bidsMax))
+	}
+	if q.itemType == SearchItemTypeFixedPrice {
+		if q.bidsMin != nil || q.bidsMax != nil {
+			conflict("bid filter on fixed price items")
+		}
+		if q.buyNowOnly {
+			conflict("buy now auctions filter on fixed price items")
+		}
+	}
+	if q.words == "" && (q.mode != "" || q.inDescription) {
+		conflict("word matching options without search words")
+	}
+	if q.perPage < 0 || q.page < 0 {
+		conflict("negative page or page size")
+	}
+	return errors.Join(errs...)
+}
+
+// AdvancedRequest returns the query as a request for SearchAdvanced.
+func (q Query) AdvancedRequest() (SearchAdvancedRequest, error) {
+	if err := q.Validate(); err != nil {
+		return SearchAdvancedRequest{}, err
+	}
+	return SearchAdvancedRequest{
+		SearchWords:            q.words,
+		CategoryID:             q.categoryID,
+		SearchInDescription:    q.inDescription,
+		Mode:                   q.mode,
+		PriceMinimum:           cloneInt32(q.priceMin),
+		PriceMaximum:           cloneInt32(q.priceMax),
+		BidsMinimum:            cloneInt32(q.bidsMin),
+		BidsMaximum:            cloneInt32(q.bidsMax),
+		ZipCode:                q.zipCode,
+		CountyID:               q.countyID,
+		Alias:                  q.seller,
+		OrderBy:                q.orderBy,
+		ItemStatus:             q.status,
+		ItemType:               q.itemType,
+		OnlyAuctionsWithBuyNow: q.buyNowOnly,
+		OnlyItemsWithThumbnail: q.thumbnailOnly,
+		ItemsPerPage:           q.perPage,
+		PageNumber:             q.page,
+		ItemCondition:          q.condition,
+		SellerType:             q.sellerType,
+		Brands:                 slices.Clone(q.brands),
+	}, nil
+}
+
+// CategoryCountRequest returns the query as a request for
+// SearchCategoryCount. Sort order and paging do not affect counts and are
+// dropped; brands cannot be counted and fail with ErrInvalidParameter.
+func (q Query) CategoryCountRequest() (CategoryCountRequest, error) {
+	if err := q.Validate(); err != nil {
+		return CategoryCountRequest{}, err
+	}
+	if len(q.brands) > 0 {
+		return CategoryCountRequest{}, fmt.Errorf("%w: brands are not supported by SearchCategoryCount", ErrInvalidParameter)
+	}
+	return CategoryCountRequest{
+		CategoryID:             q.categoryID,
+		SearchWords:            q.words,
+		Alias:                  q.seller,
+		CountyID:               q.countyID,
+		SearchInDescription:    q.inDescription,
+		ItemCondition:          q.condition,
+		ZipCode:                q.zipCode,
+		OnlyItemsWithThumbnail: q.thumbnailOnly,
+		OnlyAuctionsWithBuyNow: q.buyNowOnly,
+		Mode:                   q.mode,
+		PriceMinimum:           cloneInt32(q.priceMin),
+		PriceMaximum:           cloneInt32(q.priceMax),
+		BidsMinimum:            cloneInt32(q.bidsMin),
+		BidsMaximum:            cloneInt32(q.bidsMax),
+		ItemStatus:             q.status,
+		ItemType:               q.itemType,
+		SellerType:             q.sellerType,
+	}, nil
+}
+
+// cloneInt32 returns a copy of p, so requests do not alias the query.
+func cloneInt32(p *int32) *int32 {
+	if p == nil {
+		return nil
+	}
+	n := *p
+	return &n
+}
+
+// URL query parameter names used by Encode and ParseQuery.
+const (
+	queryParamWords         = "q"
+	queryParamMode          = "mode"
+	queryParamInDescription = "desc"
+	queryParamCategory      = "category"
+	queryParamPriceMin      = "price_min"
+	queryParamPriceMax      = "price_max"
+	queryParamBidsMin       = "bids_min"
+	queryParamBidsMax       = "bids_max"
+	queryParamZipCode       = "zip"
+	queryParamCounty        = "county"
+	queryParamSeller        = "seller"
+	queryParamSellerType    = "seller_type"
+	queryParamBrand         = "brand"
+	queryParamCondition     = "condition"
+	queryParamType          = "type"
+	queryParamStatus        = "status"
+	queryParamOrderBy       = "sort"
+	queryParamBuyNow        = "buy_now"
+	queryParamThumbnail     = "thumbnail"
+	queryParamPerPage       = "per_page"
+	queryParamPage          = "page"
+)
+
+// Values returns the query as URL query parameters. Unset filters are left out.
+func (q Query) Values() url.Values {
+	v := url.Values{}
+	setString := func(key, value string) {
+		if value != "" {
+			v.Set(key, value)
+		}
+	}
+	setInt := func(key string, n int32) {
+		if n != 0 {
+			v.Set(key, strconv.Itoa(int(n)))
+		}
+	}
+	setBound := func(key string, n *int32) {
+		if n != nil {
+			v.Set(key, strconv.Itoa(int(*n)))
+		}
+	}
+	setFlag := func(key string, on bool) {
+		if on {
+			v.Set(key, "1")
+		}
+	}
+
+	setString(queryParamWords, q.words)
+	setString(queryParamMode, string(q.mode))
+	setFlag(queryParamInDescription, q.inDescription)
+	setInt(queryParamCategory, q.categoryID)
+	setBound(queryParamPriceMin, q.priceMin)
+	setBound(queryParamPriceMax, q.priceMax)
+	setBound(queryParamBidsMin, q.bidsMin)
+	setBound(queryParamBidsMax, q.bidsMax)
+	setString(queryParamZipCode, q.zipCode)
+	setInt(queryParamCounty, q.countyID)
+	setString(queryParamSeller, q.seller)
+	setString(queryParamSellerType, string(q.sellerType))
+	for _, brand := range q.brands {
+		v.Add(queryParamBrand, brand)
+	}
+	setString(queryParamCondition, string(q.condition))
+	setString(queryParamType, string(q.itemType))
+	setString(queryParamStatus, string(q.status))
+	setString(queryParamOrderBy, string(q.orderBy))
+	setFlag(queryParamBuyNow, q.buyNowOnly)
+	setFlag(queryParamThumbnail, q.thumbnailOnly)
+	setInt(queryParamPerPage, q.perPage)
+	setInt(queryParamPage, q.page)
+	return v
+}
+
+// Encode returns the query as a URL query string, e.g.
+// "category=16&price_max=5000&q=leica".
+func (q Query) Encode() string {
+	return q.Values().Encode()
+}
+
+// MarshalText implements encoding.TextMarshaler, encoding the query like
+// Encode so it can be stored as JSON.
+func (q Query) MarshalText() ([]byte, error) {
+	return []byte(q.Encode()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, decoding like ParseQuery.
+func (q *Query) UnmarshalText(text []byte) error {
+	parsed, err := ParseQuery(string(text))
+	if err != nil {
+		return err
+	}
+	*q = parsed
+	return nil
+}
+
+// ParseQuery decodes a URL query string produced by Encode, with or without
+// a leading "?". Unknown parameters are ignored so the query can share a URL
+// with other parameters. The decoded query is validated.
+func ParseQuery(s string) (Query, error) {
+	values, err := url.ParseQuery(strings.TrimPrefix(s, "?"))
+	if err != nil {
+		return Query{}, fmt.Errorf("%w: %w", ErrInvalidParameter, err)
+	}
+	return QueryFromValues(values)
+}
+
+// QueryFromValues decodes URL query parameters produced by Values, such as
+// those of an incoming http.Request. The decoded query is validated.
+func QueryFromValues(values url.Values) (Query, error) {
+	var q Query
+	var errs []error
+
+	getInt := func(key string) *int32 {
+		s := values.Get(key)
+		if s == "" {
+			return nil
+		}
+		n, err := strconv.ParseInt(s, 10, 32)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s %q is not a number", ErrInvalidParameter, key, s))
+			return nil
+		}
+		n32 := int32(n)
+		return &n32
+	}
+	getValue := func(key string) int32 {
+		if n := getInt(key); n != nil {
+			return *n
+		}
+		return 0
+	}
+	getFlag := func(key string) bool {
+		s := values.Get(key)
+		if s == "" {
+			return false
+		}
+		on, err := strconv.ParseBool(s)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%w: %s %q is not a boolean", ErrInvalidParameter, key, s))
+		}
+		return on
+	}
+	check := func(err error) {
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+	var err error
+
+	q.words = values.Get(queryParamWords)
+	q.mode, err = ParseSearchMode(values.Get(queryParamMode))
+	check(err)
+	q.inDescription = getFlag(queryParamInDescription)
+	q.categoryID = getValue(queryParamCategory)
+	q.priceMin = getInt(queryParamPriceMin)
+	q.priceMax = getInt(queryParamPriceMax)
+	q.bidsMin = getInt(queryParamBidsMin)
+	q.bidsMax = getInt(queryParamBidsMax)
+	q.zipCode = values.Get(queryParamZipCode)
+	q.countyID = getValue(queryParamCounty)
+	q.seller = values.Get(queryParamSeller)
+	q.sellerType, err = ParseSearchSellerType(values.Get(queryParamSellerType))
+	check(err)
+	q.brands = slices.Clone(values[queryParamBrand])
+	q.condition, err = ParseSearchItemCondition(values.Get(queryParamCondition))
+	check(err)
+	q.itemType, err = ParseSearchItemType(values.Get(queryParamType))
+	check(err)
+	q.status, err = ParseSearchItemStatus(values.Get(queryParamStatus))
+	check(err)
+	q.orderBy, err = ParseSearchOrderBy(values.Get(queryParamOrderBy))
+	check(err)
+	q.buyNowOnly = getFlag(queryParamBuyNow)
+	q.thumbnailOnly = getFlag(queryParamThumbnail)
+	q.perPage = getValue(queryParamPerPage)
+	q.page = getValue(queryParamPage)
+
+	if len(errs) > 0 {
+		return Query{}, errors.Join(errs...)
+	}
+	if err := q.Validate(); err != nil {
+		return Query{}, err
+	}
+	return q, nil
+}
@@ -0,0 +1,151 @@
+package tradera_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	tradera "github.com/SebbeJohansson/tradera-go-client"
+)
+
+func TestQueryEncodeParseRoundTrip(t *testing.T) {
+	tests := []struct {
+		name    string
+		query   tradera.Query
+		encoded string
+	}{
+		{
+			name:    "empty",
+			query:   tradera.NewQuery(),
+			encoded: "",
+		},
+		{
+			name:    "words",
+			query:   tradera.NewQuery().Words("leica", "m6").Mode(tradera.SearchModeAnyWords).InDescription(),
+			encoded: "desc=1&mode=AnyWords&q=leica+m6",
+		},
+		{
+			name:    "price and bids",
+			query:   tradera.NewQuery().Price(0, 5000).Bids(1, 10),
+			encoded: "bids_max=10&bids_min=1&price_max=5000&price_min=0",
+		},
+		{
+			name:    "open ranges",
+			query:   tradera.NewQuery().MinPrice(100).MaxBids(3),
+			encoded: "bids_max=3&price_min=100",
+		},
+		{
+			name:    "location and seller",
+			query:   tradera.NewQuery().ZipCode("11122").County(1).Seller("kamerabutiken").SellerType(tradera.SearchSellerTypeOnlyBusiness),
+			encoded: "county=1&seller=kamerabutiken&seller_type=OnlyBusiness&zip=11122",
+		},
+		{
+			name:    "brands",
+			query:   tradera.NewQuery().Brands("Leica", "Zeiss Ikon"),
+			encoded: "brand=Leica&brand=Zeiss+Ikon",
+		},
+		{
+			name: "enums and flags",
+			query: tradera.NewQuery().
+				Condition(tradera.SearchItemConditionOnlyNew).
+				Type(tradera.SearchItemTypeAuction).
+				Status(tradera.SearchItemStatusActive).
+				OrderBy(tradera.SearchOrderByBidsDescending).
+				OnlyWithBuyNow().
+				OnlyWithThumbnail(),
+			encoded: "buy_now=1&condition=OnlyNew&sort=BidsDescending&status=Active&thumbnail=1&type=Auction",
+		},
+		{
+			name:    "paging",
+			query:   tradera.NewQuery().InCategory(16).PerPage(50).Page(3),
+			encoded: "category=16&page=3&per_page=50",
+		},
+		{
+			name:    "escaping",
+			query:   tradera.NewQuery().Words("blå & gul", "50%"),
+			encoded: "q=bl%C3%A5+%26+gul+50%25",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.query.Encode(); got != tt.encoded {
+				t.Fatalf("Encode() = %q, want %q", got, tt.encoded)
+			}
+
+			for _, input := range []string{tt.encoded, "?" + tt.encoded} {
+				parsed, err := tradera.ParseQuery(input)
+				if err != nil {
+					t.Fatalf("ParseQuery(%q): %v", input, err)
+				}
+				if !reflect.DeepEqual(parsed, tt.query) {
+					t.Fatalf("ParseQuery(%q) = %#v, want %#v", input, parsed, tt.query)
+				}
+			}
+
+			data, err := json.Marshal(struct{ Query tradera.Query }{tt.query})
+			if err != nil {
+				t.Fatal(err)
+			}
+			var decoded struct{ Query tradera.Query }
+			if err := json.Unmarshal(data, &decoded); err != nil {
+				t.Fatalf("unmarshaling %s: %v", data, err)
+			}
+			if !reflect.DeepEqual(decoded.Query, tt.query) {
+				t.Fatalf("JSON round trip through %s = %#v, want %#v", data, decoded.Query, tt.query)
+			}
+		})
+	}
+}
+
+func TestParseQuery(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		want    tradera.Query
+		wantErr bool
+	}{
+		{
+			name:  "enums ignore case",
+			input: "sort=priceascending&type=AUCTION",
+			want:  tradera.NewQuery().OrderBy(tradera.SearchOrderByPriceAscending).Type(tradera.SearchItemTypeAuction),
+		},
+		{
+			name:  "boolean spellings",
+			input: "buy_now=true&thumbnail=0",
+			want:  tradera.NewQuery().OnlyWithBuyNow(),
+		},
+		{
+			name:  "unknown parameters",
+			input: "q=leica&utm_source=newsletter",
+			want:  tradera.NewQuery().Words("leica"),
+		},
+		{name: "unknown enum", input: "sort=Cheapest", wantErr: true},
+		{name: "not a number", input: "price_min=cheap", wantErr: true},
+		{name: "out of range", input: "category=99999999999", wantErr: true},
+		{name: "not a boolean", input: "buy_now=maybe", wantErr: true},
+		{name: "inverted price range", input: "price_min=500&price_max=100", wantErr: true},
+		{name: "bids on fixed price items", input: "type=FixedPrice&bids_min=1", wantErr: true},
+		{name: "mode without words", input: "mode=AnyWords", wantErr: true},
+		{name: "malformed", input: "q=%zz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tradera.ParseQuery(tt.input)
+			if tt.wantErr {
+				if !errors.Is(err, tradera.ErrInvalidParameter) {
+					t.Fatalf("ParseQuery(%q): err = %v, want ErrInvalidParameter", tt.input, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("ParseQuery(%q): %v", tt.input, err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("ParseQuery(%q) = %#v, want %#v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
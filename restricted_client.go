@@ -7,20 +7,33 @@ import (
 )
 
 // RestrictedClient provides access to the Tradera Restricted API.
-// Requires user authentication (UserID and Token in config).
+// Requires user authentication (UserID and Token in config, a CredentialProvider, or WithUser per call).
 type RestrictedClient struct {
 	client  *Client
 	service restricted.RestrictedServiceSoap
 }
 
 func newRestrictedClient(c *Client) *RestrictedClient {
-	soapClient := c.createSOAPClient(RestrictedServiceURL)
+	soapClient := c.createSOAPClient(RestrictedServiceURL, c.configAuth())
 	return &RestrictedClient{
 		client:  c,
 		service: restricted.NewRestrictedServiceSoap(soapClient),
 	}
 }
 
+// op returns the operation descriptor for an action of this service.
+func (c *RestrictedClient) op(action string, request any, opts []CallOption) Call {
+	return Call{Service: ServiceRestricted, Action: action, Request: request, opts: opts, userAuth: true}
+}
+
+// serviceFor returns the service to call, honoring a per-call user from WithUser.
+func (c *RestrictedClient) serviceFor(ctx context.Context) restricted.RestrictedServiceSoap {
+	if auth := authFromContext(ctx); auth != nil {
+		return restricted.NewRestrictedServiceSoap(c.client.createSOAPClient(RestrictedServiceURL, auth))
+	}
+	return c.service
+}
+
 // SellerTransaction represents a seller transaction.
 type SellerTransaction struct {
 	ID                      int32
@@ -37,13 +50,11 @@ type SellerTransaction struct {
 }
 
 // GetSellerTransactions retrieves transactions for the authenticated seller.
-func (c *RestrictedClient) GetSellerTransactions(ctx context.Context) ([]*SellerTransaction, error) {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return nil, err
-	}
+func (c *RestrictedClient) GetSellerTransactions(ctx context.Context, opts ...CallOption) ([]*SellerTransaction, error) {
+	request := &restricted.GetSellerTransactions{}
 
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*restricted.GetSellerTransactionsResponse, error) {
-		return c.service.GetSellerTransactionsContext(ctx, &restricted.GetSellerTransactions{})
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetSellerTransactions", request, opts), func(ctx context.Context) (*restricted.GetSellerTransactionsResponse, error) {
+		return c.serviceFor(ctx).GetSellerTransactionsContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -95,13 +106,11 @@ type UserInfo struct {
 }
 
 // GetUserInfo retrieves information about the authenticated user.
-func (c *RestrictedClient) GetUserInfo(ctx context.Context) (*UserInfo, error) {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return nil, err
-	}
+func (c *RestrictedClient) GetUserInfo(ctx context.Context, opts ...CallOption) (*UserInfo, error) {
+	request := &restricted.GetUserInfo{}
 
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*restricted.GetUserInfoResponse, error) {
-		return c.service.GetUserInfoContext(ctx, &restricted.GetUserInfo{})
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetUserInfo", request, opts), func(ctx context.Context) (*restricted.GetUserInfoResponse, error) {
+		return c.serviceFor(ctx).GetUserInfoContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -145,13 +154,11 @@ type ShopSettings struct {
 }
 
 // GetShopSettings retrieves the shop settings for the authenticated user.
-func (c *RestrictedClient) GetShopSettings(ctx context.Context) (*ShopSettings, error) {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return nil, err
-	}
+func (c *RestrictedClient) GetShopSettings(ctx context.Context, opts ...CallOption) (*ShopSettings, error) {
+	request := &restricted.GetShopSettings{}
 
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*restricted.GetShopSettingsResponse, error) {
-		return c.service.GetShopSettingsContext(ctx, &restricted.GetShopSettings{})
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("GetShopSettings", request, opts), func(ctx context.Context) (*restricted.GetShopSettingsResponse, error) {
+		return c.serviceFor(ctx).GetShopSettingsContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -178,15 +185,13 @@ func (c *RestrictedClient) GetShopSettings(ctx context.Context) (*ShopSettings,
 }
 
 // EndItem ends an active item.
-func (c *RestrictedClient) EndItem(ctx context.Context, itemID int32) error {
-	if err := RequireUserAuth(c.client.config); err != nil {
-		return err
+func (c *RestrictedClient) EndItem(ctx context.Context, itemID int32, opts ...CallOption) error {
+	request := &restricted.EndItem{
+		ItemId: itemID,
 	}
 
-	return c.client.executeWithMiddleware(ctx, func() error {
-		_, err := c.service.EndItemContext(ctx, &restricted.EndItem{
-			ItemId: itemID,
-		})
+	return c.client.executeWithMiddleware(ctx, c.op("EndItem", request, opts).withItem(itemID), func(ctx context.Context) error {
+		_, err := c.serviceFor(ctx).EndItemContext(ctx, request)
 		return err
 	})
 }
@@ -0,0 +1,296 @@
+package tradera
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/SebbeJohansson/tradera-go-client/middleware"
+)
+
+const (
+	// defaultWatchInterval is how often a saved search runs if neither the
+	// search nor the watcher sets an interval.
+	defaultWatchInterval = 15 * time.Minute
+
+	// defaultWatchMaxItems is how many matches a run looks at by default.
+	defaultWatchMaxItems = 500
+
+	// watchPollInterval bounds how long the watcher sleeps, so searches
+	// added to the store are picked up and failed runs are retried.
+	watchPollInterval = time.Minute
+)
+
+// SavedSearch is a named search whose new and changed matches are reported
+// by a SearchWatcher.
+type SavedSearch struct {
+	Name  string
+	Query Query
+
+	// Interval is how often the search runs (0 = the watcher's default).
+	Interval time.Duration
+
+	// LastRun and Seen are maintained by the watcher: the time of the last
+	// successful run and the state of each match seen so far, by item ID.
+	LastRun time.Time
+	Seen    map[int32]SeenItem
+}
+
+// SeenItem is the state of a match when it was last seen.
+type SeenItem struct {
+	Price         int32 // leading bid, else opening bid, else buy now price
+	BuyItNowPrice int32 // 0 if none
+	BidCount      int32
+	EndDate       time.Time
+
+	FirstSeen time.Time // when the item first matched
+	Changed   time.Time // when the price or bids last changed, else FirstSeen
+}
+
+// MatchKind tells why a match is reported.
+type MatchKind string
+
+// Match kinds.
+const (
+	MatchNew       MatchKind = "new"
+	MatchPriceDrop MatchKind = "price_drop"
+	MatchNewBid    MatchKind = "new_bid"
+)
+
+// Match is a new or changed item found by a saved search.
+type Match struct {
+	Search   string // name of the saved search
+	Kind     MatchKind
+	Item     *SearchItem
+	Previous SeenItem // state when last seen; zero for MatchNew
+}
+
+// MatchHandler receives the matches found by a SearchWatcher.
+type MatchHandler func(ctx context.Context, m Match)
+
+// MatchChannel returns a MatchHandler that sends matches to ch. Sending
+// blocks until ch has room or the watcher's context is done.
+func MatchChannel(ch chan<- Match) MatchHandler {
+	return func(ctx context.Context, m Match) {
+		select {
+		case ch <- m:
+		case <-ctx.Done():
+		}
+	}
+}
+
+// SearchWatcher periodically runs the saved searches in a store and reports
+// items that are new since the previous run, have dropped in price or have
+// received bids. The first run of a search only records its current matches.
+//
+// Searches run one at a time through SearchAdvanced at low priority, so they
+// stay within the client's rate limit and queue behind interactive calls.
+type SearchWatcher struct {
+	client  *Client
+	store   SavedSearchStore
+	onMatch MatchHandler
+
+	// Interval is how often searches without their own interval run
+	// (default: 15 minutes).
+	Interval time.Duration
+
+	// MaxItems is how many matches of a search each run looks at
+	// (default: 500).
+	MaxItems int
+
+	// OnError is called when a run fails; the run is retried after a
+	// minute. By default failures are logged with the client's logger.
+	OnError func(ctx context.Context, search string, err error)
+}
+
+// NewSearchWatcher creates a watcher for the searches in store.
+// onMatch is called for every new or changed match; see MatchChannel.
+func NewSearchWatcher(client *Client, store SavedSearchStore, onMatch MatchHandler) *SearchWatcher {
+	return &SearchWatcher{
+		client:   client,
+		store:    store,
+		onMatch:  onMatch,
+		Interval: defaultWatchInterval,
+		MaxItems: defaultWatchMaxItems,
+	}
+}
+
+// Run runs searches as they become due until ctx is done, then returns
+// ctx.Err().
+func (w *SearchWatcher) Run(ctx context.Context) error {
+	for {
+		next := w.runDue(ctx)
+
+		timer := time.NewTimer(max(min(time.Until(next), watchPollInterval), 0))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// Check runs the named search now, records its matches and returns the new
+// and changed ones. The matches are not passed to the watcher's handler.
+func (w *SearchWatcher) Check(ctx context.Context, name string) ([]Match, error) {
+	search, ok, err := w.store.Get(name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, fmt.Errorf("%w: saved search %q", ErrNotFound, name)
+	}
+	return w.check(ctx, search)
+}
+
+// runDue runs the searches that are due and returns when the next one is.
+func (w *SearchWatcher) runDue(ctx context.Context) time.Time {
+	next := time.Now().Add(watchPollInterval)
+
+	searches, err := w.store.All()
+	if err != nil {
+		w.fail(ctx, "", err)
+		return next
+	}
+
+	for _, search := range searches {
+		if ctx.Err() != nil {
+			break
+		}
+
+		due := search.LastRun.Add(w.intervalOf(search))
+		if !time.Now().Before(due) {
+			matches, err := w.check(ctx, search)
+			if err != nil {
+				w.fail(ctx, search.Name, err)
+				due = time.Now().Add(watchPollInterval)
+			} else {
+				due = time.Now().Add(w.intervalOf(search))
+			}
+			for _, m := range matches {
+				w.onMatch(ctx, m)
+			}
+		}
+		if due.Before(next) {
+			next = due
+		}
+	}
+	return next
+}
+
+// check runs a search and stores what it saw.
+func (w *SearchWatcher) check(ctx context.Context, search SavedSearch) ([]Match, error) {
+	req, err := search.Query.AdvancedRequest()
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	first := search.LastRun.IsZero()
+	seen := make(map[int32]SeenItem, len(search.Seen))
+	var matches []Match
+
+	maxItems := w.MaxItems
+	if maxItems <= 0 {
+		maxItems = defaultWatchMaxItems
+	}
+	opts := []CallOption{WithNoCache(), WithPriority(middleware.PriorityLow)}
+	for item, err := range w.client.Search().AllAdvanced(ctx, req, maxItems, opts...) {
+		if err != nil {
+			return nil, err
+		}
+
+		previous, ok := search.Seen[item.ID]
+		current := observeItem(item, previous, ok, start)
+		seen[item.ID] = current
+
+		var kind MatchKind
+		switch {
+		case first:
+		case !ok:
+			kind = MatchNew
+		case current.BidCount > previous.BidCount:
+			kind = MatchNewBid
+		case current.Price < previous.Price,
+			current.BuyItNowPrice > 0 && current.BuyItNowPrice < previous.BuyItNowPrice:
+			kind = MatchPriceDrop
+		}
+		if kind != "" {
+			matches = append(matches, Match{Search: search.Name, Kind: kind, Item: item, Previous: previous})
+		}
+	}
+
+	// Keep items missing from this run until they end, so an item that
+	// drops out of the first MaxItems and comes back is not new again
+	for id, item := range search.Seen {
+		if _, ok := seen[id]; !ok && item.EndDate.After(start) {
+			seen[id] = item
+		}
+	}
+
+	// Update the stored search rather than the copy, which may be outdated
+	current, ok, err := w.store.Get(search.Name)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return matches, nil
+	}
+	current.LastRun = start
+	current.Seen = seen
+	if err := w.store.Put(current); err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// intervalOf returns how often search runs.
+func (w *SearchWatcher) intervalOf(search SavedSearch) time.Duration {
+	switch {
+	case search.Interval > 0:
+		return search.Interval
+	case w.Interval > 0:
+		return w.Interval
+	default:
+		return defaultWatchInterval
+	}
+}
+
+// fail reports a failed run.
+func (w *SearchWatcher) fail(ctx context.Context, search string, err error) {
+	if w.OnError != nil {
+		w.OnError(ctx, search, err)
+		return
+	}
+	w.client.logger.WarnContext(ctx, "tradera: saved search failed", "search", search, "error", err)
+}
+
+// observeItem returns the state of item seen at now to compare later runs
+// against. If the item was seen before, in state previous, its first seen
+// time is kept, as is its change time unless the price or bids changed.
+func observeItem(item *SearchItem, previous SeenItem, seenBefore bool, now time.Time) SeenItem {
+	s := SeenItem{
+		Price:     itemPrice(item),
+		BidCount:  item.BidCount,
+		EndDate:   itemEnd(item),
+		FirstSeen: now,
+		Changed:   now,
+	}
+	if item.BuyItNowPrice != nil {
+		s.BuyItNowPrice = *item.BuyItNowPrice
+	}
+
+	if seenBefore {
+		if !previous.FirstSeen.IsZero() {
+			s.FirstSeen = previous.FirstSeen
+		}
+		if s.Price == previous.Price && s.BuyItNowPrice == previous.BuyItNowPrice && s.BidCount == previous.BidCount {
+			s.Changed = previous.Changed
+			if s.Changed.IsZero() {
+				s.Changed = s.FirstSeen
+			}
+		}
+	}
+	return s
+}
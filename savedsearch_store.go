@@ -0,0 +1,189 @@
+package tradera
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"slices"
+	"strings"
+	"sync"
+)
+
+// SavedSearchStore keeps saved searches by name, together with the state a
+// SearchWatcher maintains for them. Implementations must be safe for
+// concurrent use.
+type SavedSearchStore interface {
+	// Get returns the search called name, with ok false if there is none.
+	Get(name string) (search SavedSearch, ok bool, err error)
+
+	// Put stores a search, replacing any previous one with the same name.
+	Put(search SavedSearch) error
+
+	// Delete removes the search called name.
+	Delete(name string) error
+
+	// All returns every search, sorted by name.
+	All() ([]SavedSearch, error)
+}
+
+// MemorySavedSearchStore keeps saved searches in memory.
+type MemorySavedSearchStore struct {
+	searches map[string]SavedSearch
+	mu       sync.RWMutex
+}
+
+// NewMemorySavedSearchStore creates an empty in-memory saved search store.
+func NewMemorySavedSearchStore() *MemorySavedSearchStore {
+	return &MemorySavedSearchStore{searches: make(map[string]SavedSearch)}
+}
+
+// Get returns the search called name.
+func (m *MemorySavedSearchStore) Get(name string) (SavedSearch, bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	search, ok := m.searches[name]
+	search.Seen = maps.Clone(search.Seen)
+	return search, ok, nil
+}
+
+// Put stores a search.
+func (m *MemorySavedSearchStore) Put(search SavedSearch) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	search.Seen = maps.Clone(search.Seen)
+	m.searches[search.Name] = search
+	return nil
+}
+
+// Delete removes the search called name.
+func (m *MemorySavedSearchStore) Delete(name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.searches, name)
+	return nil
+}
+
+// All returns every search, sorted by name.
+func (m *MemorySavedSearchStore) All() ([]SavedSearch, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	all := make([]SavedSearch, 0, len(m.searches))
+	for _, search := range m.searches {
+		search.Seen = maps.Clone(search.Seen)
+		all = append(all, search)
+	}
+	sortSavedSearches(all)
+	return all, nil
+}
+
+// FileSavedSearchStore persists saved searches in a JSON file. Unlike
+// FileQuotaStore it takes no file lock: every write replaces the whole file,
+// so only one process may use it at a time or changes may be lost.
+type FileSavedSearchStore struct {
+	path string
+	mu   sync.Mutex
+}
+
+// NewFileSavedSearchStore creates a saved search store backed by the file at
+// path. The file is created on the first write.
+func NewFileSavedSearchStore(path string) *FileSavedSearchStore {
+	return &FileSavedSearchStore{path: path}
+}
+
+// Get returns the search called name.
+func (f *FileSavedSearchStore) Get(name string) (SavedSearch, bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all, err := f.read()
+	if err != nil {
+		return SavedSearch{}, false, err
+	}
+	search, ok := all[name]
+	return search, ok, nil
+}
+
+// Put stores a search.
+func (f *FileSavedSearchStore) Put(search SavedSearch) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all, err := f.read()
+	if err != nil {
+		return err
+	}
+	all[search.Name] = search
+	return f.write(all)
+}
+
+// Delete removes the search called name.
+func (f *FileSavedSearchStore) Delete(name string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all, err := f.read()
+	if err != nil {
+		return err
+	}
+	if _, ok := all[name]; !ok {
+		return nil
+	}
+	delete(all, name)
+	return f.write(all)
+}
+
+// All returns every search, sorted by name.
+func (f *FileSavedSearchStore) All() ([]SavedSearch, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	all, err := f.read()
+	if err != nil {
+		return nil, err
+	}
+	searches := slices.Collect(maps.Values(all))
+	sortSavedSearches(searches)
+	return searches, nil
+}
+
+// read loads all searches from the file.
+// Must be called with mutex held.
+func (f *FileSavedSearchStore) read() (map[string]SavedSearch, error) {
+	all := make(map[string]SavedSearch)
+
+	data, err := os.ReadFile(f.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return all, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &all); err != nil {
+		return nil, fmt.Errorf("tradera: reading saved search file %s: %w", f.path, err)
+	}
+	return all, nil
+}
+
+// write saves all searches to the file.
+// Must be called with mutex held.
+func (f *FileSavedSearchStore) write(all map[string]SavedSearch) error {
+	data, err := json.MarshalIndent(all, "", "  ")
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(f.path, data)
+}
+
+// sortSavedSearches sorts searches by name.
+func sortSavedSearches(searches []SavedSearch) {
+	slices.SortFunc(searches, func(a, b SavedSearch) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+}
@@ -0,0 +1,220 @@
+package tradera_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	tradera "github.com/SebbeJohansson/tradera-go-client"
+)
+
+// Item end dates in search responses, as Swedish wall clock times.
+const (
+	endsLater    = "2099-01-01T10:00:00"
+	alreadyEnded = "2020-01-01T10:00:00"
+)
+
+// searchItem returns an item of a SearchAdvanced response.
+func searchItem(id, nextBid, bidCount int32, endDate string) string {
+	return fmt.Sprintf("<Items><Id>%d</Id><ShortDescription>Item %d</ShortDescription><NextBid>%d</NextBid><BidCount>%d</BidCount><EndDate>%s</EndDate><ItemUrl>https://www.tradera.com/item/%d</ItemUrl></Items>",
+		id, id, nextBid, bidCount, endDate, id)
+}
+
+// searchResponse returns a single page SearchAdvanced response with items.
+func searchResponse(items ...string) string {
+	return `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <SearchAdvancedResponse xmlns="http://api.tradera.com">
+      <SearchAdvancedResult>
+        <TotalNumberOfItems>` + fmt.Sprint(len(items)) + `</TotalNumberOfItems>
+        <TotalNumberOfPages>1</TotalNumberOfPages>
+        ` + strings.Join(items, "\n        ") + `
+      </SearchAdvancedResult>
+    </SearchAdvancedResponse>
+  </soap:Body>
+</soap:Envelope>`
+}
+
+// matchKinds returns the item IDs and kinds of matches.
+func matchKinds(matches []tradera.Match) []string {
+	kinds := make([]string, len(matches))
+	for i, m := range matches {
+		kinds[i] = fmt.Sprintf("%d:%s", m.Item.ID, m.Kind)
+	}
+	return kinds
+}
+
+func TestSearchWatcherCheck(t *testing.T) {
+	server := newFakeTradera(t, http.StatusOK, searchResponse(
+		searchItem(1, 100, 0, endsLater),
+		searchItem(2, 200, 0, endsLater),
+		searchItem(3, 300, 0, endsLater),
+		searchItem(4, 400, 0, endsLater),
+		searchItem(5, 500, 0, alreadyEnded),
+	))
+	store := tradera.NewMemorySavedSearchStore()
+	if err := store.Put(tradera.SavedSearch{Name: "leica", Query: tradera.NewQuery().Words("leica")}); err != nil {
+		t.Fatal(err)
+	}
+	watcher := tradera.NewSearchWatcher(newTestClient(t, server), store, nil)
+
+	check := func(want ...string) tradera.SavedSearch {
+		t.Helper()
+
+		matches, err := watcher.Check(context.Background(), "leica")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := matchKinds(matches); !slices.Equal(got, want) {
+			t.Fatalf("matches = %v, want %v", got, want)
+		}
+		search, _, err := store.Get("leica")
+		if err != nil {
+			t.Fatal(err)
+		}
+		return search
+	}
+
+	// The first run only records the current matches
+	first := check()
+	if len(first.Seen) != 5 || first.LastRun.IsZero() {
+		t.Fatalf("first run recorded %d items at %v, want 5 items", len(first.Seen), first.LastRun)
+	}
+
+	// A bid, a lower price and a new item are reported. Of the items missing
+	// from the run, the one still running is kept and the ended one dropped.
+	server.respond(searchResponse(
+		searchItem(1, 110, 1, endsLater),
+		searchItem(2, 150, 0, endsLater),
+		searchItem(3, 300, 0, endsLater),
+		searchItem(6, 600, 0, endsLater),
+	))
+	second := check("1:new_bid", "2:price_drop", "6:new")
+	if _, ok := second.Seen[4]; !ok {
+		t.Fatal("running item missing from the run was forgotten")
+	}
+	if _, ok := second.Seen[5]; ok {
+		t.Fatal("ended item missing from the run was kept")
+	}
+	if seen := second.Seen[3]; !seen.FirstSeen.Equal(first.Seen[3].FirstSeen) || !seen.Changed.Equal(first.Seen[3].Changed) {
+		t.Fatalf("unchanged item 3 = %+v, want first seen and changed kept from %+v", seen, first.Seen[3])
+	}
+	if seen := second.Seen[1]; !seen.Changed.Equal(second.LastRun) || !seen.FirstSeen.Equal(first.Seen[1].FirstSeen) {
+		t.Fatalf("bid on item 1 = %+v, want changed at the second run and first seen kept", seen)
+	}
+
+	// An item that comes back is not new again
+	server.respond(searchResponse(
+		searchItem(1, 110, 1, endsLater),
+		searchItem(4, 400, 0, endsLater),
+	))
+	check()
+}
+
+// editedStore is a saved search store whose search changes while a check
+// runs, as if edited by another goroutine.
+type editedStore struct {
+	*tradera.MemorySavedSearchStore
+	gets int
+}
+
+func (s *editedStore) Get(name string) (tradera.SavedSearch, bool, error) {
+	s.gets++
+	if s.gets == 2 {
+		search, _, _ := s.MemorySavedSearchStore.Get(name)
+		search.Interval = time.Hour
+		s.MemorySavedSearchStore.Put(search)
+	}
+	return s.MemorySavedSearchStore.Get(name)
+}
+
+func TestSearchWatcherKeepsConcurrentEdits(t *testing.T) {
+	server := newFakeTradera(t, http.StatusOK, searchResponse(searchItem(1, 100, 0, endsLater)))
+	store := &editedStore{MemorySavedSearchStore: tradera.NewMemorySavedSearchStore()}
+	if err := store.Put(tradera.SavedSearch{Name: "leica", Query: tradera.NewQuery().Words("leica")}); err != nil {
+		t.Fatal(err)
+	}
+	watcher := tradera.NewSearchWatcher(newTestClient(t, server), store, nil)
+
+	if _, err := watcher.Check(context.Background(), "leica"); err != nil {
+		t.Fatal(err)
+	}
+
+	search, _, _ := store.MemorySavedSearchStore.Get("leica")
+	if search.Interval != time.Hour {
+		t.Fatalf("interval = %v, want the edit made during the run kept", search.Interval)
+	}
+	if len(search.Seen) != 1 || search.LastRun.IsZero() {
+		t.Fatalf("run recorded %d items at %v, want 1 item", len(search.Seen), search.LastRun)
+	}
+}
+
+func TestSavedSearchStores(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "searches.json")
+	stores := []struct {
+		name   string
+		store  tradera.SavedSearchStore
+		reopen func() tradera.SavedSearchStore
+	}{
+		{"memory", tradera.NewMemorySavedSearchStore(), nil},
+		{"file", tradera.NewFileSavedSearchStore(path), func() tradera.SavedSearchStore {
+			return tradera.NewFileSavedSearchStore(path)
+		}},
+	}
+
+	for _, tt := range stores {
+		t.Run(tt.name, func(t *testing.T) {
+			seen := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+			leica := tradera.SavedSearch{
+				Name:     "leica",
+				Query:    tradera.NewQuery().Words("leica").MaxPrice(5000),
+				Interval: time.Hour,
+				LastRun:  seen,
+				Seen:     map[int32]tradera.SeenItem{1: {Price: 100, BidCount: 2, FirstSeen: seen, Changed: seen}},
+			}
+			for _, search := range []tradera.SavedSearch{leica, {Name: "contax"}} {
+				if err := tt.store.Put(search); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			// Changing a returned search does not change the stored one
+			got, ok, err := tt.store.Get("leica")
+			if err != nil || !ok {
+				t.Fatalf("Get(leica) = %v, %v", ok, err)
+			}
+			got.Seen[2] = tradera.SeenItem{}
+
+			store := tt.store
+			if tt.reopen != nil {
+				store = tt.reopen()
+			}
+			got, _, _ = store.Get("leica")
+			if !reflect.DeepEqual(got, leica) {
+				t.Fatalf("Get(leica) = %+v, want %+v", got, leica)
+			}
+
+			all, err := store.All()
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(all) != 2 || all[0].Name != "contax" || all[1].Name != "leica" {
+				t.Fatalf("All() = %+v, want contax and leica", all)
+			}
+
+			if err := store.Delete("leica"); err != nil {
+				t.Fatal(err)
+			}
+			if _, ok, _ := store.Get("leica"); ok {
+				t.Fatal("deleted search is still stored")
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package tradera
 
 import (
+	"bytes"
 	"context"
+	"encoding/gob"
+	"errors"
+	"time"
 
 	"github.com/hooklift/gowsdl/soap"
 	"github.com/SebbeJohansson/tradera-go-client/generated/search"
@@ -14,19 +18,32 @@ type SearchClient struct {
 }
 
 func newSearchClient(c *Client) *SearchClient {
-	soapClient := c.createSOAPClient(SearchServiceURL)
+	soapClient := c.createSOAPClient(SearchServiceURL, c.configAuth())
 	return &SearchClient{
 		client:  c,
 		service: search.NewSearchServiceSoap(soapClient),
 	}
 }
 
+// op returns the operation descriptor for an action of this service.
+func (c *SearchClient) op(action string, request any, opts []CallOption) Call {
+	return Call{Service: ServiceSearch, Action: action, Request: request, opts: opts}
+}
+
+// serviceFor returns the service to call, honoring a per-call user from WithUser.
+func (c *SearchClient) serviceFor(ctx context.Context) search.SearchServiceSoap {
+	if auth := authFromContext(ctx); auth != nil {
+		return search.NewSearchServiceSoap(c.client.createSOAPClient(SearchServiceURL, auth))
+	}
+	return c.service
+}
+
 // SearchRequest contains parameters for a basic search.
 type SearchRequest struct {
 	Query      string
 	CategoryID int32
 	PageNumber int32
-	OrderBy    string
+	OrderBy    SearchOrderBy
 }
 
 // SearchResult represents the result of a search operation.
@@ -59,6 +76,107 @@ type SearchItem struct {
 	ImageLinks       []ImageLink
 }
 
+// gobSearchItem is the gob representation of a SearchItem. soap.XSDDateTime
+// has no exported fields, so the end date is stored as a time.Time and a
+// flag for whether it carried a time zone. Dates without one keep their wall
+// clock time in UTC.
+type gobSearchItem struct {
+	ID               int32
+	ShortDescription string
+	LongDescription  string
+	BuyItNowPrice    *int32
+	SellerID         int32
+	SellerAlias      string
+	MaxBid           *int32
+	ThumbnailLink    string
+	SellerDsrAverage float64
+	EndDate          time.Time
+	HasEndDate       bool
+	HasTz            bool
+	NextBid          *int32
+	HasBids          bool
+	IsEnded          bool
+	ItemType         string
+	ItemURL          string
+	CategoryID       int32
+	BidCount         int32
+	ImageLinks       []ImageLink
+}
+
+// GobEncode implements gob.GobEncoder so search results can be stored in a
+// middleware.DiskCache.
+func (i SearchItem) GobEncode() ([]byte, error) {
+	// StripTz only changes dates that carry a zone
+	stripped := i.EndDate
+	stripped.StripTz()
+	hasTz := stripped != i.EndDate
+
+	end := i.EndDate.ToGoTime()
+	if !hasTz {
+		end = time.Date(end.Year(), end.Month(), end.Day(), end.Hour(), end.Minute(), end.Second(), end.Nanosecond(), time.UTC)
+	}
+	g := gobSearchItem{
+		ID:               i.ID,
+		ShortDescription: i.ShortDescription,
+		LongDescription:  i.LongDescription,
+		BuyItNowPrice:    i.BuyItNowPrice,
+		SellerID:         i.SellerID,
+		SellerAlias:      i.SellerAlias,
+		MaxBid:           i.MaxBid,
+		ThumbnailLink:    i.ThumbnailLink,
+		SellerDsrAverage: i.SellerDsrAverage,
+		EndDate:          end,
+		HasEndDate:       i.EndDate != soap.XSDDateTime{},
+		HasTz:            hasTz,
+		NextBid:          i.NextBid,
+		HasBids:          i.HasBids,
+		IsEnded:          i.IsEnded,
+		ItemType:         i.ItemType,
+		ItemURL:          i.ItemURL,
+		CategoryID:       i.CategoryID,
+		BidCount:         i.BidCount,
+		ImageLinks:       i.ImageLinks,
+	}
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(&g); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// GobDecode implements gob.GobDecoder. The end date is restored as it was
+// encoded: the same instant if it had a time zone, otherwise the same wall
+// clock time without one.
+func (i *SearchItem) GobDecode(data []byte) error {
+	var g gobSearchItem
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&g); err != nil {
+		return err
+	}
+	*i = SearchItem{
+		ID:               g.ID,
+		ShortDescription: g.ShortDescription,
+		LongDescription:  g.LongDescription,
+		BuyItNowPrice:    g.BuyItNowPrice,
+		SellerID:         g.SellerID,
+		SellerAlias:      g.SellerAlias,
+		MaxBid:           g.MaxBid,
+		ThumbnailLink:    g.ThumbnailLink,
+		SellerDsrAverage: g.SellerDsrAverage,
+		NextBid:          g.NextBid,
+		HasBids:          g.HasBids,
+		IsEnded:          g.IsEnded,
+		ItemType:         g.ItemType,
+		ItemURL:          g.ItemURL,
+		CategoryID:       g.CategoryID,
+		BidCount:         g.BidCount,
+		ImageLinks:       g.ImageLinks,
+	}
+	if g.HasEndDate {
+		i.EndDate = soap.CreateXsdDateTime(g.EndDate, g.HasTz)
+	}
+	return nil
+}
+
 // ImageLink represents an image URL with format information.
 type ImageLink struct {
 	URL    string
@@ -72,23 +190,29 @@ type SearchError struct {
 }
 
 // Search performs a basic item search.
-func (c *SearchClient) Search(ctx context.Context, query string, categoryID int32) (*SearchResult, error) {
+func (c *SearchClient) Search(ctx context.Context, query string, categoryID int32, opts ...CallOption) (*SearchResult, error) {
 	return c.SearchWithOptions(ctx, SearchRequest{
 		Query:      query,
 		CategoryID: categoryID,
 		PageNumber: 1,
-	})
+	}, opts...)
 }
 
 // SearchWithOptions performs a search with custom options.
-func (c *SearchClient) SearchWithOptions(ctx context.Context, req SearchRequest) (*SearchResult, error) {
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*search.SearchResponse, error) {
-		return c.service.SearchContext(ctx, &search.Search{
-			Query:      req.Query,
-			CategoryId: req.CategoryID,
-			PageNumber: req.PageNumber,
-			OrderBy:    req.OrderBy,
-		})
+func (c *SearchClient) SearchWithOptions(ctx context.Context, req SearchRequest, opts ...CallOption) (*SearchResult, error) {
+	if err := checkEnum("OrderBy", searchOrderByValues, req.OrderBy); err != nil {
+		return nil, err
+	}
+
+	request := &search.Search{
+		Query:      req.Query,
+		CategoryId: req.CategoryID,
+		PageNumber: req.PageNumber,
+		OrderBy:    string(req.OrderBy),
+	}
+
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("Search", request, opts), func(ctx context.Context) (*search.SearchResponse, error) {
+		return c.serviceFor(ctx).SearchContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -102,7 +226,7 @@ type SearchAdvancedRequest struct {
 	SearchWords            string
 	CategoryID             int32
 	SearchInDescription    bool
-	Mode                   string // "AllWords" or "AnyWords"
+	Mode                   SearchMode
 	PriceMinimum           *int32
 	PriceMaximum           *int32
 	BidsMinimum            *int32
@@ -110,25 +234,36 @@ type SearchAdvancedRequest struct {
 	ZipCode                string
 	CountyID               int32
 	Alias                  string
-	OrderBy                string
-	ItemStatus             string // "Active" or "Ended"
-	ItemType               string // "All", "Auction", "FixedPrice"
+	OrderBy                SearchOrderBy
+	ItemStatus             SearchItemStatus
+	ItemType               SearchItemType
 	OnlyAuctionsWithBuyNow bool
 	OnlyItemsWithThumbnail bool
 	ItemsPerPage           int32
 	PageNumber             int32
-	ItemCondition          string // "All", "OnlyNew", "OnlySecondHand"
-	SellerType             string // "All", "OnlyPrivate", "OnlyBusiness"
+	ItemCondition          SearchItemCondition
+	SellerType             SearchSellerType
 	Brands                 []string
 }
 
 // SearchAdvanced performs an advanced search with filters.
-func (c *SearchClient) SearchAdvanced(ctx context.Context, req SearchAdvancedRequest) (*SearchResult, error) {
+// Unknown enum values fail with ErrInvalidParameter before anything is sent.
+func (c *SearchClient) SearchAdvanced(ctx context.Context, req SearchAdvancedRequest, opts ...CallOption) (*SearchResult, error) {
+	return c.searchAdvanced(ctx, req, "", opts)
+}
+
+// searchAdvanced performs an advanced search, caching the result under
+// cacheKey unless it is empty.
+func (c *SearchClient) searchAdvanced(ctx context.Context, req SearchAdvancedRequest, cacheKey string, opts []CallOption) (*SearchResult, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
 	advReq := &search.SearchAdvancedRequest{
 		SearchWords:            req.SearchWords,
 		CategoryId:             req.CategoryID,
 		SearchInDescription:    req.SearchInDescription,
-		Mode:                   req.Mode,
+		Mode:                   string(req.Mode),
 		PriceMinimum:           req.PriceMinimum,
 		PriceMaximum:           req.PriceMaximum,
 		BidsMinimum:            req.BidsMinimum,
@@ -136,15 +271,15 @@ func (c *SearchClient) SearchAdvanced(ctx context.Context, req SearchAdvancedReq
 		ZipCode:                req.ZipCode,
 		CountyId:               req.CountyID,
 		Alias:                  req.Alias,
-		OrderBy:                req.OrderBy,
-		ItemStatus:             req.ItemStatus,
-		ItemType:               req.ItemType,
+		OrderBy:                string(req.OrderBy),
+		ItemStatus:             string(req.ItemStatus),
+		ItemType:               string(req.ItemType),
 		OnlyAuctionsWithBuyNow: req.OnlyAuctionsWithBuyNow,
 		OnlyItemsWithThumbnail: req.OnlyItemsWithThumbnail,
 		ItemsPerPage:           req.ItemsPerPage,
 		PageNumber:             req.PageNumber,
-		ItemCondition:          req.ItemCondition,
-		SellerType:             req.SellerType,
+		ItemCondition:          string(req.ItemCondition),
+		SellerType:             string(req.SellerType),
 	}
 
 	if len(req.Brands) > 0 {
@@ -155,16 +290,19 @@ func (c *SearchClient) SearchAdvanced(ctx context.Context, req SearchAdvancedReq
 		advReq.Brands = &search.ArrayOfString{Astring: brands}
 	}
 
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*search.SearchAdvancedResponse, error) {
-		return c.service.SearchAdvancedContext(ctx, &search.SearchAdvanced{
-			Request: advReq,
-		})
-	})
-	if err != nil {
-		return nil, err
+	request := &search.SearchAdvanced{
+		Request: advReq,
 	}
 
-	return convertSearchResult(result.SearchAdvancedResult), nil
+	op := c.op("SearchAdvanced", request, opts).withCache(cacheKey)
+	return executeWithMiddlewareResult(c.client, ctx, op, func(ctx context.Context) (*SearchResult, error) {
+		result, err := c.serviceFor(ctx).SearchAdvancedContext(ctx, request)
+		if err != nil {
+			return nil, err
+		}
+
+		return convertSearchResult(result.SearchAdvancedResult), nil
+	})
 }
 
 // CategoryCountRequest contains parameters for a category count search.
@@ -174,18 +312,18 @@ type CategoryCountRequest struct {
 	Alias                  string
 	CountyID               int32
 	SearchInDescription    bool
-	ItemCondition          string
+	ItemCondition          SearchItemCondition
 	ZipCode                string
 	OnlyItemsWithThumbnail bool
 	OnlyAuctionsWithBuyNow bool
-	Mode                   string
+	Mode                   SearchMode
 	PriceMinimum           *int32
 	PriceMaximum           *int32
 	BidsMinimum            *int32
 	BidsMaximum            *int32
-	ItemStatus             string
-	ItemType               string
-	SellerType             string
+	ItemStatus             SearchItemStatus
+	ItemType               SearchItemType
+	SellerType             SearchSellerType
 }
 
 // CategoryCountResult represents the result of a category count search.
@@ -204,29 +342,36 @@ type SearchCategory struct {
 }
 
 // SearchCategoryCount gets item counts per category.
-func (c *SearchClient) SearchCategoryCount(ctx context.Context, req CategoryCountRequest) (*CategoryCountResult, error) {
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*search.SearchCategoryCountResponse, error) {
-		return c.service.SearchCategoryCountContext(ctx, &search.SearchCategoryCount{
-			Request: &search.CategoryCountRequest{
-				CategoryId:             req.CategoryID,
-				SearchWords:            req.SearchWords,
-				Alias:                  req.Alias,
-				CountyId:               req.CountyID,
-				SearchInDescription:    req.SearchInDescription,
-				ItemCondition:          req.ItemCondition,
-				ZipCode:                req.ZipCode,
-				OnlyItemsWithThumbnail: req.OnlyItemsWithThumbnail,
-				OnlyAuctionsWithBuyNow: req.OnlyAuctionsWithBuyNow,
-				Mode:                   req.Mode,
-				PriceMinimum:           req.PriceMinimum,
-				PriceMaximum:           req.PriceMaximum,
-				BidsMinimum:            req.BidsMinimum,
-				BidsMaximum:            req.BidsMaximum,
-				ItemStatus:             req.ItemStatus,
-				ItemType:               req.ItemType,
-				SellerType:             req.SellerType,
-			},
-		})
+// Unknown enum values fail with ErrInvalidParameter before anything is sent.
+func (c *SearchClient) SearchCategoryCount(ctx context.Context, req CategoryCountRequest, opts ...CallOption) (*CategoryCountResult, error) {
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	request := &search.SearchCategoryCount{
+		Request: &search.CategoryCountRequest{
+			CategoryId:             req.CategoryID,
+			SearchWords:            req.SearchWords,
+			Alias:                  req.Alias,
+			CountyId:               req.CountyID,
+			SearchInDescription:    req.SearchInDescription,
+			ItemCondition:          string(req.ItemCondition),
+			ZipCode:                req.ZipCode,
+			OnlyItemsWithThumbnail: req.OnlyItemsWithThumbnail,
+			OnlyAuctionsWithBuyNow: req.OnlyAuctionsWithBuyNow,
+			Mode:                   string(req.Mode),
+			PriceMinimum:           req.PriceMinimum,
+			PriceMaximum:           req.PriceMaximum,
+			BidsMinimum:            req.BidsMinimum,
+			BidsMaximum:            req.BidsMaximum,
+			ItemStatus:             string(req.ItemStatus),
+			ItemType:               string(req.ItemType),
+			SellerType:             string(req.SellerType),
+		},
+	}
+
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("SearchCategoryCount", request, opts), func(ctx context.Context) (*search.SearchCategoryCountResponse, error) {
+		return c.serviceFor(ctx).SearchCategoryCountContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -236,15 +381,21 @@ func (c *SearchClient) SearchCategoryCount(ctx context.Context, req CategoryCoun
 }
 
 // SearchByZipCode searches items by zip code.
-func (c *SearchClient) SearchByZipCode(ctx context.Context, zipCode string, pageNumber int32, orderBy string) (*SearchResult, error) {
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*search.SearchByZipCodeResponse, error) {
-		return c.service.SearchByZipCodeContext(ctx, &search.SearchByZipCode{
-			Request: &search.SearchByZipCodeRequest{
-				ZipCode:    zipCode,
-				PageNumber: pageNumber,
-				OrderBy:    orderBy,
-			},
-		})
+func (c *SearchClient) SearchByZipCode(ctx context.Context, zipCode string, pageNumber int32, orderBy SearchOrderBy, opts ...CallOption) (*SearchResult, error) {
+	if err := checkEnum("OrderBy", searchOrderByValues, orderBy); err != nil {
+		return nil, err
+	}
+
+	request := &search.SearchByZipCode{
+		Request: &search.SearchByZipCodeRequest{
+			ZipCode:    zipCode,
+			PageNumber: pageNumber,
+			OrderBy:    string(orderBy),
+		},
+	}
+
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("SearchByZipCode", request, opts), func(ctx context.Context) (*search.SearchByZipCodeResponse, error) {
+		return c.serviceFor(ctx).SearchByZipCodeContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -254,16 +405,25 @@ func (c *SearchClient) SearchByZipCode(ctx context.Context, zipCode string, page
 }
 
 // SearchByFixedCriteria searches items by predefined criteria.
-func (c *SearchClient) SearchByFixedCriteria(ctx context.Context, name string, pageNumber int32, itemType string, orderBy string) (*SearchResult, error) {
-	result, err := executeWithMiddlewareResult(c.client, ctx, func() (*search.SearchByFixedCriteriaResponse, error) {
-		return c.service.SearchByFixedCriteriaContext(ctx, &search.SearchByFixedCriteria{
-			Request: &search.SearchByFixedCriteriaRequest{
-				Name:       name,
-				PageNumber: pageNumber,
-				ItemType:   itemType,
-				OrderBy:    orderBy,
-			},
-		})
+func (c *SearchClient) SearchByFixedCriteria(ctx context.Context, name string, pageNumber int32, itemType SearchItemType, orderBy SearchOrderBy, opts ...CallOption) (*SearchResult, error) {
+	if err := errors.Join(
+		checkEnum("ItemType", searchItemTypeValues, itemType),
+		checkEnum("OrderBy", searchOrderByValues, orderBy),
+	); err != nil {
+		return nil, err
+	}
+
+	request := &search.SearchByFixedCriteria{
+		Request: &search.SearchByFixedCriteriaRequest{
+			Name:       name,
+			PageNumber: pageNumber,
+			ItemType:   string(itemType),
+			OrderBy:    string(orderBy),
+		},
+	}
+
+	result, err := executeWithMiddlewareResult(c.client, ctx, c.op("SearchByFixedCriteria", request, opts), func(ctx context.Context) (*search.SearchByFixedCriteriaResponse, error) {
+		return c.serviceFor(ctx).SearchByFixedCriteriaContext(ctx, request)
 	})
 	if err != nil {
 		return nil, err
@@ -0,0 +1,64 @@
+package tradera_test
+
+import (
+	"bytes"
+	"encoding/gob"
+	"encoding/xml"
+	"strings"
+	"testing"
+	"time"
+
+	tradera "github.com/SebbeJohansson/tradera-go-client"
+	"github.com/hooklift/gowsdl/soap"
+)
+
+func TestSearchItemGobRoundTrip(t *testing.T) {
+	wallClock := time.Date(2026, 5, 4, 12, 30, 0, 0, time.UTC)
+	tests := []struct {
+		name    string
+		endDate soap.XSDDateTime
+		want    string // the end date as sent in XML
+	}{
+		{"without zone", soap.CreateXsdDateTime(wallClock, false), "2026-05-04T12:30:00"},
+		{"with zone", soap.CreateXsdDateTime(wallClock.In(time.FixedZone("", 2*60*60)), true), "2026-05-04T14:30:00+02:00"},
+		{"with UTC", soap.CreateXsdDateTime(wallClock, true), "2026-05-04T12:30:00Z"},
+		{"no end date", soap.XSDDateTime{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &tradera.SearchItem{ID: 5, ShortDescription: "Leica", EndDate: tt.endDate, ItemURL: "https://x/5"}
+
+			var buf bytes.Buffer
+			if err := gob.NewEncoder(&buf).Encode(item); err != nil {
+				t.Fatal(err)
+			}
+			var decoded tradera.SearchItem
+			if err := gob.NewDecoder(&buf).Decode(&decoded); err != nil {
+				t.Fatal(err)
+			}
+
+			if decoded.ID != item.ID || decoded.ShortDescription != item.ShortDescription || decoded.ItemURL != item.ItemURL {
+				t.Fatalf("decoded %+v, want %+v", decoded, *item)
+			}
+			got := marshalDate(t, decoded.EndDate)
+			if want := marshalDate(t, tt.endDate); got != want || !strings.Contains(want, tt.want) {
+				t.Fatalf("decoded end date marshals as %s, want %s", got, tt.want)
+			}
+			if got, want := decoded.EndDate.ToGoTime(), tt.endDate.ToGoTime(); !got.Equal(want) {
+				t.Fatalf("decoded end date = %v, want %v", got, want)
+			}
+		})
+	}
+}
+
+// marshalDate returns d as the XML sent to the API.
+func marshalDate(t *testing.T, d soap.XSDDateTime) string {
+	t.Helper()
+
+	data, err := xml.Marshal(d)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
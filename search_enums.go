@@ -0,0 +1,211 @@
+package tradera
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// SearchOrderBy is the sort order of search results.
+type SearchOrderBy string
+
+// Search orders.
+const (
+	SearchOrderByEndDateAscending  SearchOrderBy = "EndDateAscending"
+	SearchOrderByEndDateDescending SearchOrderBy = "EndDateDescending"
+	SearchOrderByPriceAscending    SearchOrderBy = "PriceAscending"
+	SearchOrderByPriceDescending   SearchOrderBy = "PriceDescending"
+	SearchOrderByBidsDescending    SearchOrderBy = "BidsDescending"
+)
+
+// SearchMode controls how multiple search words are matched.
+type SearchMode string
+
+// Search modes.
+const (
+	SearchModeAllWords SearchMode = "AllWords"
+	SearchModeAnyWords SearchMode = "AnyWords"
+)
+
+// SearchItemStatus selects active or ended items.
+type SearchItemStatus string
+
+// Search item statuses.
+const (
+	SearchItemStatusActive SearchItemStatus = "Active"
+	SearchItemStatusEnded  SearchItemStatus = "Ended"
+)
+
+// SearchItemType selects auctions, fixed price items or both.
+type SearchItemType string
+
+// Search item types.
+const (
+	SearchItemTypeAll        SearchItemType = "All"
+	SearchItemTypeAuction    SearchItemType = "Auction"
+	SearchItemTypeFixedPrice SearchItemType = "FixedPrice"
+)
+
+// SearchItemCondition selects new or second hand items.
+type SearchItemCondition string
+
+// Search item conditions.
+const (
+	SearchItemConditionAll            SearchItemCondition = "All"
+	SearchItemConditionOnlyNew        SearchItemCondition = "OnlyNew"
+	SearchItemConditionOnlySecondHand SearchItemCondition = "OnlySecondHand"
+)
+
+// SearchSellerType selects private or business sellers.
+type SearchSellerType string
+
+// Search seller types.
+const (
+	SearchSellerTypeAll          SearchSellerType = "All"
+	SearchSellerTypeOnlyPrivate  SearchSellerType = "OnlyPrivate"
+	SearchSellerTypeOnlyBusiness SearchSellerType = "OnlyBusiness"
+)
+
+// Allowed values of each search enum, in documentation order.
+var (
+	searchOrderByValues = []SearchOrderBy{
+		SearchOrderByEndDateAscending,
+		SearchOrderByEndDateDescending,
+		SearchOrderByPriceAscending,
+		SearchOrderByPriceDescending,
+		SearchOrderByBidsDescending,
+	}
+	searchModeValues = []SearchMode{
+		SearchModeAllWords,
+		SearchModeAnyWords,
+	}
+	searchItemStatusValues = []SearchItemStatus{
+		SearchItemStatusActive,
+		SearchItemStatusEnded,
+	}
+	searchItemTypeValues = []SearchItemType{
+		SearchItemTypeAll,
+		SearchItemTypeAuction,
+		SearchItemTypeFixedPrice,
+	}
+	searchItemConditionValues = []SearchItemCondition{
+		SearchItemConditionAll,
+		SearchItemConditionOnlyNew,
+		SearchItemConditionOnlySecondHand,
+	}
+	searchSellerTypeValues = []SearchSellerType{
+		SearchSellerTypeAll,
+		SearchSellerTypeOnlyPrivate,
+		SearchSellerTypeOnlyBusiness,
+	}
+)
+
+// String returns the value as sent to the API.
+func (o SearchOrderBy) String() string { return string(o) }
+
+// String returns the value as sent to the API.
+func (m SearchMode) String() string { return string(m) }
+
+// String returns the value as sent to the API.
+func (s SearchItemStatus) String() string { return string(s) }
+
+// String returns the value as sent to the API.
+func (t SearchItemType) String() string { return string(t) }
+
+// String returns the value as sent to the API.
+func (c SearchItemCondition) String() string { return string(c) }
+
+// String returns the value as sent to the API.
+func (t SearchSellerType) String() string { return string(t) }
+
+// ParseSearchOrderBy parses a sort order, ignoring case. An empty string
+// parses to the empty value, which leaves the API default.
+func ParseSearchOrderBy(s string) (SearchOrderBy, error) {
+	return parseEnum("OrderBy", searchOrderByValues, s)
+}
+
+// ParseSearchMode parses a search mode, ignoring case. An empty string
+// parses to the empty value, which leaves the API default.
+func ParseSearchMode(s string) (SearchMode, error) {
+	return parseEnum("Mode", searchModeValues, s)
+}
+
+// ParseSearchItemStatus parses an item status, ignoring case. An empty
+// string parses to the empty value, which leaves the API default.
+func ParseSearchItemStatus(s string) (SearchItemStatus, error) {
+	return parseEnum("ItemStatus", searchItemStatusValues, s)
+}
+
+// ParseSearchItemType parses an item type, ignoring case. An empty string
+// parses to the empty value, which leaves the API default.
+func ParseSearchItemType(s string) (SearchItemType, error) {
+	return parseEnum("ItemType", searchItemTypeValues, s)
+}
+
+// ParseSearchItemCondition parses an item condition, ignoring case. An
+// empty string parses to the empty value, which leaves the API default.
+func ParseSearchItemCondition(s string) (SearchItemCondition, error) {
+	return parseEnum("ItemCondition", searchItemConditionValues, s)
+}
+
+// ParseSearchSellerType parses a seller type, ignoring case. An empty string
+// parses to the empty value, which leaves the API default.
+func ParseSearchSellerType(s string) (SearchSellerType, error) {
+	return parseEnum("SellerType", searchSellerTypeValues, s)
+}
+
+// validate checks the enum fields of the request.
+func (r *SearchAdvancedRequest) validate() error {
+	return errors.Join(
+		checkEnum("Mode", searchModeValues, r.Mode),
+		checkEnum("OrderBy", searchOrderByValues, r.OrderBy),
+		checkEnum("ItemStatus", searchItemStatusValues, r.ItemStatus),
+		checkEnum("ItemType", searchItemTypeValues, r.ItemType),
+		checkEnum("ItemCondition", searchItemConditionValues, r.ItemCondition),
+		checkEnum("SellerType", searchSellerTypeValues, r.SellerType),
+	)
+}
+
+// validate checks the enum fields of the request.
+func (r *CategoryCountRequest) validate() error {
+	return errors.Join(
+		checkEnum("Mode", searchModeValues, r.Mode),
+		checkEnum("ItemStatus", searchItemStatusValues, r.ItemStatus),
+		checkEnum("ItemType", searchItemTypeValues, r.ItemType),
+		checkEnum("ItemCondition", searchItemConditionValues, r.ItemCondition),
+		checkEnum("SellerType", searchSellerTypeValues, r.SellerType),
+	)
+}
+
+// parseEnum returns the value matching s, ignoring case and surrounding
+// white space.
+func parseEnum[T ~string](field string, values []T, s string) (T, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return "", nil
+	}
+	for _, v := range values {
+		if strings.EqualFold(string(v), s) {
+			return v, nil
+		}
+	}
+	return "", invalidEnum(field, values, s)
+}
+
+// checkEnum fails if v is neither empty nor one of values.
+func checkEnum[T ~string](field string, values []T, v T) error {
+	if v == "" || slices.Contains(values, v) {
+		return nil
+	}
+	return invalidEnum(field, values, string(v))
+}
+
+// invalidEnum describes an unknown value of field.
+func invalidEnum[T ~string](field string, values []T, s string) error {
+	allowed := make([]string, len(values))
+	for i, v := range values {
+		allowed[i] = string(v)
+	}
+	return fmt.Errorf("%w: unknown %s %q (want one of %s)", ErrInvalidParameter, field, s, strings.Join(allowed, ", "))
+}
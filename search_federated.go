@@ -0,0 +1,108 @@
+package tradera
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sync"
+)
+
+// FederatedResult is the merged result of several searches.
+type FederatedResult struct {
+	Items []*FederatedItem
+
+	// Totals holds the TotalNumberOfItems of each request, by index;
+	// 0 for requests that failed.
+	Totals []int32
+
+	Errors []*SearchError
+}
+
+// FederatedItem is an item of a federated search, with the requests that
+// matched it.
+type FederatedItem struct {
+	*SearchItem
+
+	// Queries holds the indexes of the requests that returned the item,
+	// in ascending order.
+	Queries []int
+}
+
+// SearchFederated runs several advanced searches, e.g. the same words in
+// several categories or several synonyms, with up to workers concurrent
+// calls (default 4), and merges their items. An item returned by several
+// requests appears once, listing all of them in Queries.
+//
+// Items are sorted by ranking, such as ByEndDate or ByPrice; with a nil
+// ranking they keep the order of the requests and their results. If some
+// requests fail, the items of the others are returned together with the
+// request errors joined (see QueryError); if all fail, the result is nil.
+func (c *SearchClient) SearchFederated(ctx context.Context, reqs []SearchAdvancedRequest, workers int, ranking ItemRanking, opts ...CallOption) (*FederatedResult, error) {
+	if workers <= 0 {
+		workers = defaultSearchWorkers
+	}
+
+	results := make([]*SearchResult, len(reqs))
+	queryErrs := make([]error, len(reqs))
+
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, req := range reqs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+			case <-ctx.Done():
+				queryErrs[i] = &QueryError{Query: i, Err: ctx.Err()}
+				return
+			}
+
+			result, err := c.SearchAdvanced(ctx, req, opts...)
+			if err != nil {
+				queryErrs[i] = &QueryError{Query: i, Err: err}
+				return
+			}
+			results[i] = result
+		}()
+	}
+	wg.Wait()
+
+	err := errors.Join(queryErrs...)
+	if len(reqs) > 0 && !slices.ContainsFunc(results, func(r *SearchResult) bool { return r != nil }) {
+		return nil, err
+	}
+
+	merged := &FederatedResult{Totals: make([]int32, len(reqs))}
+	byID := make(map[int32]*FederatedItem)
+	for i, result := range results {
+		if result == nil {
+			continue
+		}
+		merged.Totals[i] = result.TotalNumberOfItems
+		merged.Errors = append(merged.Errors, result.Errors...)
+
+		for _, item := range result.Items {
+			if item == nil {
+				continue
+			}
+			if existing, dup := byID[item.ID]; dup {
+				if !slices.Contains(existing.Queries, i) {
+					existing.Queries = append(existing.Queries, i)
+				}
+				continue
+			}
+			fi := &FederatedItem{SearchItem: item, Queries: []int{i}}
+			byID[item.ID] = fi
+			merged.Items = append(merged.Items, fi)
+		}
+	}
+
+	if ranking != nil {
+		slices.SortStableFunc(merged.Items, func(a, b *FederatedItem) int {
+			return ranking(a.SearchItem, b.SearchItem)
+		})
+	}
+	return merged, err
+}
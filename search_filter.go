@@ -0,0 +1,306 @@
+package tradera
+
+import (
+	"cmp"
+	"context"
+	"iter"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hooklift/gowsdl/soap"
+)
+
+// ItemFilter reports whether a search result should be kept. Filters run
+// client side, for criteria the search API cannot express.
+type ItemFilter func(item *SearchItem) bool
+
+// AllOf keeps items kept by every filter.
+func AllOf(filters ...ItemFilter) ItemFilter {
+	return func(item *SearchItem) bool {
+		for _, keep := range filters {
+			if !keep(item) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyOf keeps items kept by at least one filter.
+func AnyOf(filters ...ItemFilter) ItemFilter {
+	return func(item *SearchItem) bool {
+		for _, keep := range filters {
+			if keep(item) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// Not keeps the items filter drops.
+func Not(filter ItemFilter) ItemFilter {
+	return func(item *SearchItem) bool {
+		return !filter(item)
+	}
+}
+
+// FilterItems returns the items kept by all filters, in order.
+func FilterItems(items []*SearchItem, filters ...ItemFilter) []*SearchItem {
+	keep := AllOf(filters...)
+	var kept []*SearchItem
+	for _, item := range items {
+		if item != nil && keep(item) {
+			kept = append(kept, item)
+		}
+	}
+	return kept
+}
+
+// ExcludeWords drops items whose title or description contains any of the
+// words, ignoring case. A word only matches whole words, so "case" does not
+// drop "showcase"; a phrase such as "for parts" matches consecutive words.
+func ExcludeWords(words ...string) ItemFilter {
+	var needles []string
+	for _, word := range words {
+		if needle := normalizeWords(word); strings.TrimSpace(needle) != "" {
+			needles = append(needles, needle)
+		}
+	}
+	return func(item *SearchItem) bool {
+		text := normalizeWords(item.ShortDescription + " " + item.LongDescription)
+		for _, needle := range needles {
+			if strings.Contains(text, needle) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// OnlySellers keeps items sold by one of the members with the given
+// aliases, ignoring case.
+func OnlySellers(aliases ...string) ItemFilter {
+	set := aliasSet(aliases)
+	return func(item *SearchItem) bool {
+		_, ok := set[strings.ToLower(item.SellerAlias)]
+		return ok
+	}
+}
+
+// ExcludeSellers drops items sold by the members with the given aliases,
+// ignoring case.
+func ExcludeSellers(aliases ...string) ItemFilter {
+	return Not(OnlySellers(aliases...))
+}
+
+// MinSellerDSR keeps items whose seller has a detailed seller rating
+// average of at least min.
+func MinSellerDSR(min float64) ItemFilter {
+	return func(item *SearchItem) bool {
+		return item.SellerDsrAverage >= min
+	}
+}
+
+// EndsWithin keeps items that have not ended and end within d from when the
+// filter runs.
+func EndsWithin(d time.Duration) ItemFilter {
+	return func(item *SearchItem) bool {
+		end := itemEnd(item)
+		now := time.Now()
+		return !item.IsEnded && !end.IsZero() && end.After(now) && !end.After(now.Add(d))
+	}
+}
+
+// HasImages keeps items with a thumbnail or at least one image.
+func HasImages() ItemFilter {
+	return func(item *SearchItem) bool {
+		return item.ThumbnailLink != "" || len(item.ImageLinks) > 0
+	}
+}
+
+// PriceBetween keeps items whose current price, as used by ByPrice, is
+// within min..max SEK, inclusive.
+func PriceBetween(min, max int32) ItemFilter {
+	return func(item *SearchItem) bool {
+		price := itemPrice(item)
+		return price >= min && price <= max
+	}
+}
+
+// MaxPricePerUnit keeps items whose current price divided by the number of
+// units, as counted by units, is at most max SEK. units may be nil to use
+// UnitsFromTitle.
+func MaxPricePerUnit(max float64, units func(item *SearchItem) float64) ItemFilter {
+	return func(item *SearchItem) bool {
+		return pricePerUnit(item, units) <= max
+	}
+}
+
+// unitsPattern matches quantities in titles such as "10 st", "5-pack",
+// "3 pcs", "2x" and "x4".
+var unitsPattern = regexp.MustCompile(`(?i)\b(\d+)\s*-?\s*(?:st|stk|styck|pcs|pack)\b|\b(\d+)\s*x\b|\bx\s*(\d+)\b`)
+
+// UnitsFromTitle returns the number of units an item's title mentions,
+// such as 10 for "Tennisbollar 10 st", or 1 if it mentions none.
+func UnitsFromTitle(item *SearchItem) float64 {
+	match := unitsPattern.FindStringSubmatch(item.ShortDescription)
+	for _, group := range match[min(1, len(match)):] {
+		if n, err := strconv.Atoi(group); err == nil && n > 0 {
+			return float64(n)
+		}
+	}
+	return 1
+}
+
+// ItemRanking orders search results, returning a negative number if a
+// ranks before b, a positive number if after, and zero if they tie.
+type ItemRanking func(a, b *SearchItem) int
+
+// SortItems sorts items by the rankings, each breaking the ties of the
+// previous one. Items that tie on every ranking keep their order.
+func SortItems(items []*SearchItem, rankings ...ItemRanking) {
+	slices.SortStableFunc(items, ThenBy(rankings...))
+}
+
+// ThenBy combines rankings, each breaking the ties of the previous one.
+func ThenBy(rankings ...ItemRanking) ItemRanking {
+	return func(a, b *SearchItem) int {
+		for _, rank := range rankings {
+			if c := rank(a, b); c != 0 {
+				return c
+			}
+		}
+		return 0
+	}
+}
+
+// Reverse reverses a ranking.
+func Reverse(ranking ItemRanking) ItemRanking {
+	return func(a, b *SearchItem) int {
+		return ranking(b, a)
+	}
+}
+
+// ByEndDate ranks items ending first first. Items without an end date rank last.
+func ByEndDate(a, b *SearchItem) int {
+	endA, endB := itemEnd(a), itemEnd(b)
+	if endA.IsZero() || endB.IsZero() {
+		return cmp.Compare(boolRank(endA.IsZero()), boolRank(endB.IsZero()))
+	}
+	return endA.Compare(endB)
+}
+
+// ByPrice ranks the cheapest items first, by the leading bid, else the
+// opening bid, else the buy now price.
+func ByPrice(a, b *SearchItem) int {
+	return cmp.Compare(itemPrice(a), itemPrice(b))
+}
+
+// ByBidCount ranks the items with the most bids first.
+func ByBidCount(a, b *SearchItem) int {
+	return cmp.Compare(b.BidCount, a.BidCount)
+}
+
+// BySellerDSR ranks the items of the best rated sellers first.
+func BySellerDSR(a, b *SearchItem) int {
+	return cmp.Compare(b.SellerDsrAverage, a.SellerDsrAverage)
+}
+
+// ByPricePerUnit ranks the items with the lowest price per unit first.
+// units may be nil to use UnitsFromTitle.
+func ByPricePerUnit(units func(item *SearchItem) float64) ItemRanking {
+	return func(a, b *SearchItem) int {
+		return cmp.Compare(pricePerUnit(a, units), pricePerUnit(b, units))
+	}
+}
+
+// AllMatching is like AllAdvanced, but yields only the items kept by filter
+// and stops after limit of them (0 = no limit). Pages keep being fetched
+// until enough items are kept or the results run out, so a strict filter
+// can make many calls.
+func (c *SearchClient) AllMatching(ctx context.Context, req SearchAdvancedRequest, limit int, filter ItemFilter, opts ...CallOption) iter.Seq2[*SearchItem, error] {
+	return paginate(ctx, req.PageNumber, limit, filter, func(ctx context.Context, page int32) (*SearchResult, error) {
+		req.PageNumber = page
+		return c.SearchAdvanced(ctx, req, opts...)
+	})
+}
+
+// CollectMatching collects up to n items kept by filter; see AllMatching.
+// On error it returns the items collected so far with the error.
+func (c *SearchClient) CollectMatching(ctx context.Context, req SearchAdvancedRequest, n int, filter ItemFilter, opts ...CallOption) ([]*SearchItem, error) {
+	var items []*SearchItem
+	for item, err := range c.AllMatching(ctx, req, n, filter, opts...) {
+		if err != nil {
+			return items, err
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// itemPrice returns what item costs now: the leading bid, else the opening
+// bid, else the buy now price.
+func itemPrice(item *SearchItem) int32 {
+	switch {
+	case item.HasBids && item.MaxBid != nil:
+		return *item.MaxBid
+	case item.NextBid != nil && *item.NextBid > 0:
+		return *item.NextBid
+	case item.BuyItNowPrice != nil:
+		return *item.BuyItNowPrice
+	default:
+		return 0
+	}
+}
+
+// itemEnd returns when item ends, in Swedish time, or the zero time if it
+// has no end date.
+func itemEnd(item *SearchItem) time.Time {
+	if item.EndDate == (soap.XSDDateTime{}) {
+		return time.Time{}
+	}
+	return inTraderaZone(item.EndDate.ToGoTime())
+}
+
+// pricePerUnit returns the current price of item per unit.
+func pricePerUnit(item *SearchItem, units func(item *SearchItem) float64) float64 {
+	if units == nil {
+		units = UnitsFromTitle
+	}
+	n := units(item)
+	if n <= 0 {
+		n = 1
+	}
+	return float64(itemPrice(item)) / n
+}
+
+// normalizeWords lowercases text and reduces it to its words separated by
+// single spaces, with a space before and after.
+func normalizeWords(text string) string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	return " " + strings.Join(words, " ") + " "
+}
+
+// aliasSet returns the lowercased aliases as a set.
+func aliasSet(aliases []string) map[string]struct{} {
+	set := make(map[string]struct{}, len(aliases))
+	for _, alias := range aliases {
+		set[strings.ToLower(alias)] = struct{}{}
+	}
+	return set
+}
+
+// boolRank orders false before true.
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
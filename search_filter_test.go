@@ -0,0 +1,76 @@
+package tradera_test
+
+import (
+	"testing"
+	"time"
+
+	tradera "github.com/SebbeJohansson/tradera-go-client"
+	"github.com/hooklift/gowsdl/soap"
+)
+
+// endingAt returns a search item with id that ends at end, reported without
+// a time zone as the search API does.
+func endingAt(id int32, end time.Time) *tradera.SearchItem {
+	item := &tradera.SearchItem{ID: id}
+	if !end.IsZero() {
+		item.EndDate = soap.CreateXsdDateTime(end, false)
+	}
+	return item
+}
+
+func TestByEndDate(t *testing.T) {
+	base := time.Date(2026, 5, 4, 12, 30, 0, 0, time.UTC)
+	items := []*tradera.SearchItem{
+		endingAt(1, base.Add(2*time.Hour)),
+		endingAt(2, time.Time{}),
+		endingAt(3, base),
+		endingAt(4, base.Add(time.Hour)),
+		endingAt(5, base.Add(time.Hour)),
+	}
+
+	tradera.SortItems(items, tradera.ByEndDate)
+
+	want := []int32{3, 4, 5, 1, 2}
+	for i, item := range items {
+		if item.ID != want[i] {
+			got := make([]int32, len(items))
+			for j, item := range items {
+				got[j] = item.ID
+			}
+			t.Fatalf("sorted by end date: %v, want %v", got, want)
+		}
+	}
+}
+
+func TestEndsWithin(t *testing.T) {
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+	// End dates are Swedish wall clock times without a zone
+	now := time.Now().In(stockholm)
+	wallClock := func(d time.Duration) time.Time {
+		t := now.Add(d)
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, time.UTC)
+	}
+
+	tests := []struct {
+		name string
+		item *tradera.SearchItem
+		want bool
+	}{
+		{"ends soon", endingAt(1, wallClock(30*time.Minute)), true},
+		{"ends later", endingAt(2, wallClock(3*time.Hour)), false},
+		{"ended", endingAt(3, wallClock(-time.Minute)), false},
+		{"no end date", endingAt(4, time.Time{}), false},
+	}
+
+	keep := tradera.EndsWithin(time.Hour)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := keep(tt.item); got != tt.want {
+				t.Fatalf("EndsWithin(1h) = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,82 @@
+package tradera
+
+import (
+	"context"
+	"iter"
+)
+
+// All returns an iterator over the items matching req, starting at
+// req.PageNumber (default 1) and fetching further pages as the loop
+// advances. It stops after limit items (0 = no limit). Items that shift
+// onto the next page while auctions end are yielded only once.
+//
+// Every page is a separate call through the client's rate limiter and
+// middleware. A failed page or a cancelled ctx yields the error and ends
+// the iteration.
+func (c *SearchClient) All(ctx context.Context, req SearchRequest, limit int, opts ...CallOption) iter.Seq2[*SearchItem, error] {
+	return paginate(ctx, req.PageNumber, limit, nil, func(ctx context.Context, page int32) (*SearchResult, error) {
+		req.PageNumber = page
+		return c.SearchWithOptions(ctx, req, opts...)
+	})
+}
+
+// AllAdvanced is like All for an advanced search.
+func (c *SearchClient) AllAdvanced(ctx context.Context, req SearchAdvancedRequest, limit int, opts ...CallOption) iter.Seq2[*SearchItem, error] {
+	return paginate(ctx, req.PageNumber, limit, nil, func(ctx context.Context, page int32) (*SearchResult, error) {
+		req.PageNumber = page
+		return c.SearchAdvanced(ctx, req, opts...)
+	})
+}
+
+// paginate iterates over the items of consecutive result pages, starting
+// at page start, de-duplicated by item ID and kept by filter (nil keeps all).
+// limit counts the items yielded.
+func paginate(ctx context.Context, start int32, limit int, filter ItemFilter, fetch func(ctx context.Context, page int32) (*SearchResult, error)) iter.Seq2[*SearchItem, error] {
+	return func(yield func(*SearchItem, error) bool) {
+		seen := make(map[int32]struct{})
+		yielded := 0
+		page := max(start, 1)
+
+		for {
+			if err := ctx.Err(); err != nil {
+				yield(nil, err)
+				return
+			}
+
+			result, err := fetch(ctx, page)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			if result == nil || len(result.Items) == 0 {
+				return
+			}
+
+			for _, item := range result.Items {
+				if item == nil {
+					continue
+				}
+				if _, dup := seen[item.ID]; dup {
+					continue
+				}
+				seen[item.ID] = struct{}{}
+				if filter != nil && !filter(item) {
+					continue
+				}
+
+				if !yield(item, nil) {
+					return
+				}
+				yielded++
+				if limit > 0 && yielded >= limit {
+					return
+				}
+			}
+
+			if page >= result.TotalNumberOfPages {
+				return
+			}
+			page++
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package tradera
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+// defaultSearchWorkers is the number of pages fetched concurrently if the
+// caller does not say.
+const defaultSearchWorkers = 4
+
+// SearchParallel fetches every page of a search, starting at req.PageNumber
+// (default 1). The first page is fetched alone to learn TotalNumberOfPages;
+// the rest are fetched by up to workers concurrent calls (default 4), each
+// going through the client's rate limiter.
+//
+// Items are returned in page order without duplicates. If some pages fail,
+// the items of the other pages are returned together with the page errors
+// joined (see PageError); if the first page fails, the result is nil.
+func (c *SearchClient) SearchParallel(ctx context.Context, req SearchRequest, workers int, opts ...CallOption) (*SearchResult, error) {
+	return fetchPagesParallel(ctx, req.PageNumber, workers, func(ctx context.Context, page int32) (*SearchResult, error) {
+		req.PageNumber = page
+		return c.SearchWithOptions(ctx, req, opts...)
+	})
+}
+
+// SearchAdvancedParallel is like SearchParallel for an advanced search.
+func (c *SearchClient) SearchAdvancedParallel(ctx context.Context, req SearchAdvancedRequest, workers int, opts ...CallOption) (*SearchResult, error) {
+	return fetchPagesParallel(ctx, req.PageNumber, workers, func(ctx context.Context, page int32) (*SearchResult, error) {
+		req.PageNumber = page
+		return c.SearchAdvanced(ctx, req, opts...)
+	})
+}
+
+// fetchPagesParallel fetches pages start..TotalNumberOfPages with a bounded
+// worker pool and merges them in page order.
+func fetchPagesParallel(ctx context.Context, start int32, workers int, fetch func(ctx context.Context, page int32) (*SearchResult, error)) (*SearchResult, error) {
+	if workers <= 0 {
+		workers = defaultSearchWorkers
+	}
+	start = max(start, 1)
+
+	first, err := fetch(ctx, start)
+	if err != nil {
+		return nil, &PageError{Page: start, Err: err}
+	}
+	if first == nil {
+		return &SearchResult{}, nil
+	}
+
+	remaining := max(int(first.TotalNumberOfPages-start), 0)
+	pages := make([]*SearchResult, remaining)
+	pageErrs := make([]error, remaining)
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for range min(workers, remaining) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				page := start + 1 + int32(i)
+				result, err := fetch(ctx, page)
+				if err != nil {
+					pageErrs[i] = &PageError{Page: page, Err: err}
+					continue
+				}
+				pages[i] = result
+			}
+		}()
+	}
+
+	var cancelled error
+dispatch:
+	for i := range remaining {
+		select {
+		case jobs <- i:
+		case <-ctx.Done():
+			// Report the pages never started once, not one error per page
+			cancelled = ctx.Err()
+			break dispatch
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	merged := &SearchResult{
+		TotalNumberOfItems: first.TotalNumberOfItems,
+		TotalNumberOfPages: first.TotalNumberOfPages,
+	}
+	seen := make(map[int32]struct{})
+	for _, result := range append([]*SearchResult{first}, pages...) {
+		if result == nil {
+			continue
+		}
+		for _, item := range result.Items {
+			if item == nil {
+				continue
+			}
+			if _, dup := seen[item.ID]; dup {
+				continue
+			}
+			seen[item.ID] = struct{}{}
+			merged.Items = append(merged.Items, item)
+		}
+		merged.Errors = append(merged.Errors, result.Errors...)
+	}
+
+	errs := pageErrs
+	if cancelled != nil {
+		errs = append(errs, cancelled)
+	}
+	return merged, errors.Join(errs...)
+}
@@ -0,0 +1,30 @@
+package tradera
+
+import (
+	"sync"
+	"time"
+)
+
+// traderaLocation returns Swedish time, the zone Tradera reports in, or nil
+// if tzdata is not available. It is loaded once.
+var traderaLocation = sync.OnceValue(func() *time.Location {
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		return nil
+	}
+	return stockholm
+})
+
+// inTraderaZone reinterprets a time without zone information, which the
+// soap package places in time.Local, as Swedish time. Times with a zone, or
+// without tzdata available, are unchanged.
+func inTraderaZone(t time.Time) time.Time {
+	if t.Location() != time.Local {
+		return t
+	}
+	stockholm := traderaLocation()
+	if stockholm == nil {
+		return t
+	}
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), t.Nanosecond(), stockholm)
+}
@@ -0,0 +1,228 @@
+package tradera
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TokenLoginPath is the path of Tradera's token login page.
+const TokenLoginPath = "/tokenlogin.aspx"
+
+// loginCookie holds the state identifying a started login in the browser.
+const loginCookie = "tradera_login"
+
+// defaultLoginTTL is how long a started login can be completed.
+const defaultLoginTTL = 15 * time.Minute
+
+// Token login errors.
+var (
+	// ErrLoginRejected is returned when the user declined to authorize the application.
+	ErrLoginRejected = errors.New("tradera: token login rejected by user")
+
+	// ErrLoginNotStarted is returned when a callback arrives without a
+	// matching login started by this TokenLogin, or after it expired.
+	ErrLoginNotStarted = errors.New("tradera: no pending token login")
+)
+
+// TokenResult is the outcome of a successful token login.
+type TokenResult struct {
+	UserID  int
+	Token   string
+	Expires time.Time // zero if Tradera did not report an expiry
+}
+
+// Credentials returns the result as Credentials.
+func (r *TokenResult) Credentials() Credentials {
+	return Credentials{UserID: r.UserID, Token: r.Token, Expires: r.Expires}
+}
+
+// TokenLoginResultFunc receives the outcome of a login callback and writes
+// the response to the user's browser. err is ErrLoginRejected if the user
+// declined.
+type TokenLoginResultFunc func(w http.ResponseWriter, r *http.Request, result *TokenResult, err error)
+
+// TokenLogin drives Tradera's token login flow for web applications:
+// StartHandler sends the user to Tradera with a fresh secret key, and
+// CallbackHandler exchanges the key for a token once the user returns.
+type TokenLogin struct {
+	client    *Client
+	publicKey string
+	onResult  TokenLoginResultFunc
+
+	// TTL is how long a started login can be completed (default: 15 minutes).
+	TTL time.Duration
+
+	mu      sync.Mutex
+	pending map[string]pendingLogin // by state cookie value
+}
+
+// pendingLogin is a login started by StartHandler.
+type pendingLogin struct {
+	secretKey string
+	expires   time.Time
+}
+
+// NewTokenLogin creates a token login helper for the application's public
+// key (shown in Tradera's developer center). onResult is called for every
+// callback with the token or an error.
+func NewTokenLogin(client *Client, publicKey string, onResult TokenLoginResultFunc) *TokenLogin {
+	return &TokenLogin{
+		client:    client,
+		publicKey: publicKey,
+		onResult:  onResult,
+		TTL:       defaultLoginTTL,
+		pending:   make(map[string]pendingLogin),
+	}
+}
+
+// LoginURL returns the Tradera login URL for a new random secret key,
+// together with the key. Use it when handling the redirect yourself;
+// Exchange then turns the key into a token.
+func (l *TokenLogin) LoginURL() (loginURL, secretKey string, err error) {
+	secretKey, err = randomHex(16)
+	if err != nil {
+		return "", "", err
+	}
+
+	query := url.Values{}
+	query.Set("appId", strconv.Itoa(l.client.config.AppID))
+	query.Set("pkey", l.publicKey)
+	query.Set("skey", secretKey)
+
+	return l.client.config.baseURL() + TokenLoginPath + "?" + query.Encode(), secretKey, nil
+}
+
+// Exchange fetches the token for a user who logged in with secretKey.
+func (l *TokenLogin) Exchange(ctx context.Context, userID int, secretKey string) (*TokenResult, error) {
+	token, err := l.client.Public().FetchToken(ctx, int32(userID), secretKey)
+	if err != nil {
+		return nil, err
+	}
+	return &TokenResult{UserID: userID, Token: token}, nil
+}
+
+// StartHandler returns a handler that redirects the user to Tradera's login
+// page. The secret key stays on the server; the browser only gets a cookie
+// identifying the login.
+func (l *TokenLogin) StartHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		loginURL, secretKey, err := l.LoginURL()
+		if err != nil {
+			http.Error(w, "failed to start login", http.StatusInternalServerError)
+			return
+		}
+		state, err := randomHex(16)
+		if err != nil {
+			http.Error(w, "failed to start login", http.StatusInternalServerError)
+			return
+		}
+
+		l.mu.Lock()
+		l.prune()
+		l.pending[state] = pendingLogin{secretKey: secretKey, expires: time.Now().Add(l.TTL)}
+		l.mu.Unlock()
+
+		http.SetCookie(w, &http.Cookie{
+			Name:     loginCookie,
+			Value:    state,
+			Path:     "/",
+			MaxAge:   int(l.TTL.Seconds()),
+			HttpOnly: true,
+			Secure:   r.TLS != nil,
+			SameSite: http.SameSiteLaxMode,
+		})
+		http.Redirect(w, r, loginURL, http.StatusFound)
+	})
+}
+
+// CallbackHandler returns a handler for the accept and reject return URLs
+// configured for the application. A request with a userId parameter is an
+// accepted login and is exchanged through FetchToken; the optional exp
+// parameter sets the token expiry. Any other request is a rejection.
+func (l *TokenLogin) CallbackHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		result, err := l.callback(r)
+		l.onResult(w, r, result, err)
+	})
+}
+
+// callback completes the login started in the browser that sent r.
+func (l *TokenLogin) callback(r *http.Request) (*TokenResult, error) {
+	var secretKey string
+	if cookie, err := r.Cookie(loginCookie); err == nil {
+		l.mu.Lock()
+		login, ok := l.pending[cookie.Value]
+		delete(l.pending, cookie.Value)
+		l.mu.Unlock()
+		if ok && time.Now().Before(login.expires) {
+			secretKey = login.secretKey
+		}
+	}
+
+	query := r.URL.Query()
+	userIDParam := query.Get("userId")
+	if userIDParam == "" {
+		return nil, ErrLoginRejected
+	}
+	if secretKey == "" {
+		return nil, ErrLoginNotStarted
+	}
+
+	userID, err := strconv.Atoi(userIDParam)
+	if err != nil || userID <= 0 {
+		return nil, errors.New("tradera: invalid userId in login callback")
+	}
+
+	result, err := l.Exchange(r.Context(), userID, secretKey)
+	if err != nil {
+		return nil, err
+	}
+	result.Expires = parseLoginExpiry(query.Get("exp"))
+	return result, nil
+}
+
+// prune removes expired logins.
+// Must be called with mutex held.
+func (l *TokenLogin) prune() {
+	now := time.Now()
+	for state, login := range l.pending {
+		if now.After(login.expires) {
+			delete(l.pending, state)
+		}
+	}
+}
+
+// loginExpiryLayouts are the formats accepted for the exp callback parameter.
+var loginExpiryLayouts = []string{time.RFC3339, "2006-01-02T15:04:05", "2006-01-02 15:04:05", "2006-01-02"}
+
+// parseLoginExpiry parses the exp callback parameter, returning the zero
+// time if it is missing or malformed. Times without a zone are Swedish time,
+// or UTC if tzdata is not available.
+func parseLoginExpiry(exp string) time.Time {
+	loc := traderaLocation()
+	if loc == nil {
+		loc = time.UTC
+	}
+	for _, layout := range loginExpiryLayouts {
+		if t, err := time.ParseInLocation(layout, exp, loc); err == nil {
+			return t
+		}
+	}
+	return time.Time{}
+}
+
+// randomHex returns n random bytes, hex-encoded.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
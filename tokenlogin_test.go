@@ -0,0 +1,280 @@
+package tradera_test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	tradera "github.com/SebbeJohansson/tradera-go-client"
+)
+
+const fetchTokenResponse = `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <FetchTokenResponse xmlns="http://api.tradera.com">
+      <FetchTokenResult>user-token</FetchTokenResult>
+    </FetchTokenResponse>
+  </soap:Body>
+</soap:Envelope>`
+
+const fetchTokenFault = `<?xml version="1.0" encoding="utf-8"?>
+<soap:Envelope xmlns:soap="http://schemas.xmlsoap.org/soap/envelope/">
+  <soap:Body>
+    <soap:Fault>
+      <faultcode>soap:Client</faultcode>
+      <faultstring>Invalid secret key</faultstring>
+    </soap:Fault>
+  </soap:Body>
+</soap:Envelope>`
+
+// fakeTradera is a test server standing in for the Tradera API. It answers
+// every SOAP call with status and body and counts the calls.
+type fakeTradera struct {
+	*httptest.Server
+	calls    atomic.Int32
+	lastBody atomic.Value // string
+	body     atomic.Value // string
+}
+
+func newFakeTradera(t *testing.T, status int, body string) *fakeTradera {
+	t.Helper()
+
+	f := &fakeTradera{}
+	f.body.Store(body)
+	f.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		f.calls.Add(1)
+		request, _ := io.ReadAll(r.Body)
+		f.lastBody.Store(string(request))
+
+		w.Header().Set("Content-Type", "text/xml; charset=utf-8")
+		w.WriteHeader(status)
+		io.WriteString(w, f.body.Load().(string))
+	}))
+	t.Cleanup(f.Close)
+	return f
+}
+
+// respond makes the server answer later calls with body.
+func (f *fakeTradera) respond(body string) {
+	f.body.Store(body)
+}
+
+// newTestClient returns a client talking to server.
+func newTestClient(t *testing.T, server *fakeTradera) *tradera.Client {
+	t.Helper()
+
+	config := tradera.DefaultConfig(1234, "app-key")
+	config.BaseURL = server.URL
+	client, err := tradera.NewClient(config)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(client.Close)
+	return client
+}
+
+// loginResult is what a TokenLogin passed to its result callback.
+type loginResult struct {
+	result *tradera.TokenResult
+	err    error
+}
+
+// newTestLogin returns a TokenLogin against server and the channel its
+// results are sent to.
+func newTestLogin(t *testing.T, server *fakeTradera) (*tradera.TokenLogin, *tradera.Client, <-chan loginResult) {
+	t.Helper()
+
+	client := newTestClient(t, server)
+	results := make(chan loginResult, 1)
+	login := tradera.NewTokenLogin(client, "public-key", func(w http.ResponseWriter, r *http.Request, result *tradera.TokenResult, err error) {
+		results <- loginResult{result, err}
+	})
+	return login, client, results
+}
+
+// startLogin runs the start handler and returns the secret key sent to
+// Tradera and the state cookie set in the browser.
+func startLogin(t *testing.T, login *tradera.TokenLogin) (string, *http.Cookie) {
+	t.Helper()
+
+	rec := httptest.NewRecorder()
+	login.StartHandler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/login", nil))
+	if rec.Code != http.StatusFound {
+		t.Fatalf("start handler returned status %d, want %d", rec.Code, http.StatusFound)
+	}
+
+	location, err := url.Parse(rec.Header().Get("Location"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if location.Path != tradera.TokenLoginPath {
+		t.Fatalf("redirected to %s, want the token login page", location)
+	}
+	query := location.Query()
+	if query.Get("appId") != "1234" || query.Get("pkey") != "public-key" || query.Get("skey") == "" {
+		t.Fatalf("login URL query %v lacks the app ID, public key or secret key", query)
+	}
+
+	cookies := rec.Result().Cookies()
+	if len(cookies) != 1 {
+		t.Fatalf("start handler set %d cookies, want 1", len(cookies))
+	}
+	return query.Get("skey"), cookies[0]
+}
+
+// callback runs the callback handler for a browser returning with query
+// and the given state cookie, and returns the result it reported.
+func callback(t *testing.T, login *tradera.TokenLogin, results <-chan loginResult, query string, cookie *http.Cookie) loginResult {
+	t.Helper()
+
+	req := httptest.NewRequest(http.MethodGet, "/callback?"+query, nil)
+	if cookie != nil {
+		req.AddCookie(cookie)
+	}
+	login.CallbackHandler().ServeHTTP(httptest.NewRecorder(), req)
+
+	select {
+	case r := <-results:
+		return r
+	default:
+		t.Fatal("callback did not report a result")
+		return loginResult{}
+	}
+}
+
+func TestTokenLoginSuccess(t *testing.T) {
+	server := newFakeTradera(t, http.StatusOK, fetchTokenResponse)
+	login, _, results := newTestLogin(t, server)
+
+	secretKey, cookie := startLogin(t, login)
+	got := callback(t, login, results, "userId=42&exp=2030-01-02T03:04:05Z", cookie)
+	if got.err != nil {
+		t.Fatalf("callback failed: %v", got.err)
+	}
+
+	want := tradera.TokenResult{UserID: 42, Token: "user-token", Expires: time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)}
+	if got.result.UserID != want.UserID || got.result.Token != want.Token || !got.result.Expires.Equal(want.Expires) {
+		t.Fatalf("result = %+v, want %+v", *got.result, want)
+	}
+
+	body, _ := server.lastBody.Load().(string)
+	if !strings.Contains(body, "<secretKey>"+secretKey+"</secretKey>") || !strings.Contains(body, "<userId>42</userId>") {
+		t.Fatalf("FetchToken request does not carry the user and secret key:\n%s", body)
+	}
+
+	// The login can only be completed once
+	if got := callback(t, login, results, "userId=42", cookie); !errors.Is(got.err, tradera.ErrLoginNotStarted) {
+		t.Fatalf("second callback: err = %v, want ErrLoginNotStarted", got.err)
+	}
+}
+
+func TestTokenLoginExpiryWithoutZone(t *testing.T) {
+	stockholm, err := time.LoadLocation("Europe/Stockholm")
+	if err != nil {
+		t.Skip("no tzdata:", err)
+	}
+
+	// Expiry times without a zone are Swedish time
+	tests := []struct {
+		exp  string
+		want time.Time
+	}{
+		{"2030-01-02T03:04:05", time.Date(2030, 1, 2, 2, 4, 5, 0, time.UTC)},
+		{"2030-07-02 03:04:05", time.Date(2030, 7, 2, 1, 4, 5, 0, time.UTC)},
+		{"2030-01-02", time.Date(2030, 1, 2, 0, 0, 0, 0, stockholm)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.exp, func(t *testing.T) {
+			server := newFakeTradera(t, http.StatusOK, fetchTokenResponse)
+			login, _, results := newTestLogin(t, server)
+
+			_, cookie := startLogin(t, login)
+			got := callback(t, login, results, "userId=42&exp="+url.QueryEscape(tt.exp), cookie)
+			if got.err != nil {
+				t.Fatalf("callback failed: %v", got.err)
+			}
+			if !got.result.Expires.Equal(tt.want) {
+				t.Fatalf("Expires = %v, want %v", got.result.Expires, tt.want)
+			}
+		})
+	}
+}
+
+func TestTokenLoginExpiredToken(t *testing.T) {
+	server := newFakeTradera(t, http.StatusOK, fetchTokenResponse)
+	login, client, results := newTestLogin(t, server)
+
+	_, cookie := startLogin(t, login)
+	got := callback(t, login, results, "userId=42&exp=2020-01-02T03:04:05Z", cookie)
+	if got.err != nil {
+		t.Fatalf("callback failed: %v", got.err)
+	}
+
+	creds := got.result.Credentials()
+	if !creds.Expired() {
+		t.Fatalf("credentials expiring %v are not expired", creds.Expires)
+	}
+
+	// Calls with the expired token fail before anything is sent
+	calls := server.calls.Load()
+	_, err := client.Buyer().Buy(context.Background(), 1, 100, tradera.WithUserCredentials(creds))
+	if !errors.Is(err, tradera.ErrTokenExpired) {
+		t.Fatalf("Buy with an expired token: err = %v, want ErrTokenExpired", err)
+	}
+	if server.calls.Load() != calls {
+		t.Fatal("Buy with an expired token reached the API")
+	}
+}
+
+func TestTokenLoginExpiredLogin(t *testing.T) {
+	server := newFakeTradera(t, http.StatusOK, fetchTokenResponse)
+	login, _, results := newTestLogin(t, server)
+	login.TTL = time.Millisecond
+
+	_, cookie := startLogin(t, login)
+	time.Sleep(5 * time.Millisecond)
+
+	got := callback(t, login, results, "userId=42", cookie)
+	if !errors.Is(got.err, tradera.ErrLoginNotStarted) {
+		t.Fatalf("callback after the login expired: err = %v, want ErrLoginNotStarted", got.err)
+	}
+	if server.calls.Load() != 0 {
+		t.Fatal("expired login was exchanged for a token")
+	}
+}
+
+func TestTokenLoginRejected(t *testing.T) {
+	server := newFakeTradera(t, http.StatusOK, fetchTokenResponse)
+	login, _, results := newTestLogin(t, server)
+
+	_, cookie := startLogin(t, login)
+	if got := callback(t, login, results, "", cookie); !errors.Is(got.err, tradera.ErrLoginRejected) {
+		t.Fatalf("callback without a user: err = %v, want ErrLoginRejected", got.err)
+	}
+}
+
+func TestTokenLoginSOAPFault(t *testing.T) {
+	server := newFakeTradera(t, http.StatusInternalServerError, fetchTokenFault)
+	login, _, results := newTestLogin(t, server)
+
+	_, cookie := startLogin(t, login)
+	got := callback(t, login, results, "userId=42", cookie)
+
+	var fault *tradera.SOAPFault
+	if !errors.As(got.err, &fault) {
+		t.Fatalf("callback: err = %v, want a *SOAPFault", got.err)
+	}
+	if fault.FaultString != "Invalid secret key" {
+		t.Fatalf("fault string = %q, want %q", fault.FaultString, "Invalid secret key")
+	}
+	if got.result != nil {
+		t.Fatalf("callback returned a result with the fault: %+v", *got.result)
+	}
+}
@@ -0,0 +1,85 @@
+package tradera
+
+import "context"
+
+// Attribute keys recorded on operation spans.
+const (
+	AttrService        = "tradera.service"
+	AttrSOAPAction     = "tradera.soap_action"
+	AttrItemID         = "tradera.item_id"
+	AttrUserID         = "tradera.user_id"
+	AttrOrderID        = "tradera.order_id"
+	AttrAuthUserID     = "tradera.auth.user_id"
+	AttrRetryAttempts  = "tradera.retry.attempts"
+	AttrCacheHit       = "tradera.cache.hit"
+	AttrRateLimitWait  = "tradera.ratelimit.wait_ms"
+	AttrErrorType      = "tradera.error.type"
+	AttrQuotaRemaining = "tradera.quota.remaining"
+)
+
+// Attribute is a key-value pair attached to a span.
+type Attribute struct {
+	Key   string
+	Value any
+}
+
+// Attr creates an Attribute.
+func Attr(key string, value any) Attribute {
+	return Attribute{Key: key, Value: value}
+}
+
+// Tracer starts spans for Tradera API operations.
+// Adapt it to OpenTelemetry or another tracing backend; the default is a no-op.
+type Tracer interface {
+	// Start starts a span and returns a context carrying it.
+	Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span)
+}
+
+// Span is a single traced operation.
+type Span interface {
+	// SetAttributes adds attributes to the span.
+	SetAttributes(attrs ...Attribute)
+
+	// RecordError records an error on the span and marks it as failed.
+	RecordError(err error)
+
+	// End completes the span.
+	End()
+}
+
+// NoopTracer is a Tracer that records nothing.
+type NoopTracer struct{}
+
+// Start returns ctx unchanged and a span that records nothing.
+func (NoopTracer) Start(ctx context.Context, name string, attrs ...Attribute) (context.Context, Span) {
+	return ctx, noopSpan{}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetAttributes(attrs ...Attribute) {}
+func (noopSpan) RecordError(err error)            {}
+func (noopSpan) End()                             {}
+
+// spanName returns the span name for an operation, e.g. "PublicService/GetItem".
+func (call *Call) spanName() string {
+	return call.Service + "/" + call.Action
+}
+
+// attributes returns the span attributes describing an operation.
+func (call *Call) attributes() []Attribute {
+	attrs := []Attribute{
+		Attr(AttrService, call.Service),
+		Attr(AttrSOAPAction, call.Action),
+	}
+	if call.ItemID != 0 {
+		attrs = append(attrs, Attr(AttrItemID, call.ItemID))
+	}
+	if call.UserID != 0 {
+		attrs = append(attrs, Attr(AttrUserID, call.UserID))
+	}
+	if call.OrderID != 0 {
+		attrs = append(attrs, Attr(AttrOrderID, call.OrderID))
+	}
+	return attrs
+}
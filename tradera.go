@@ -13,6 +13,9 @@
 //   - Optional rate limiting
 //   - Optional automatic retry with exponential backoff
 //   - Optional response caching
+//   - Optional tracing of every API call through a pluggable Tracer
+//   - Optional metrics with a built-in Prometheus exposition handler
+//   - Composable interceptors around every call and hooks on the HTTP transport
 //
 // Basic usage:
 //
@@ -31,14 +34,21 @@ package tradera
 
 import (
 	"context"
+	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/hooklift/gowsdl/soap"
 	"github.com/SebbeJohansson/tradera-go-client/middleware"
 )
 
+// DefaultBaseURL is the address of the Tradera API.
+const DefaultBaseURL = "https://api.tradera.com"
+
 // WSDL URLs for Tradera services
 const (
 	SearchServiceURL     = "https://api.tradera.com/v3/SearchService.asmx"
@@ -49,19 +59,40 @@ const (
 	BuyerServiceURL      = "https://api.tradera.com/v3/BuyerService.asmx"
 )
 
+// Service names identify the Tradera API services, e.g. as keys in Config.Quotas.
+const (
+	ServiceSearch     = "SearchService"
+	ServicePublic     = "PublicService"
+	ServiceListing    = "ListingService"
+	ServiceRestricted = "RestrictedService"
+	ServiceOrder      = "OrderService"
+	ServiceBuyer      = "BuyerService"
+)
+
 // Client is the main Tradera API client.
 // It provides access to all Tradera services with optional middleware support.
 type Client struct {
 	config Config
 
 	// Middleware
-	rateLimiter *middleware.RateLimiter
+	limiter     middleware.Limiter
+	quota       *middleware.QuotaManager
 	retryer     *middleware.Retryer
-	cache       *middleware.Cache
+	retryConfig middleware.RetryConfig
+	cache       middleware.CacheStore
+	ownsCache   bool // true if the cache was created by NewClient
+	loader      *middleware.CacheLoader
+	invoker     Invoker // interceptor chain ending in send
 
 	// HTTP client
 	httpClient *http.Client
 
+	tracer  Tracer
+	metrics Metrics
+	logger  *slog.Logger
+
+	warnedTokens sync.Map // tokenKey -> struct{}, tokens OnTokenExpiring was called for
+
 	// Lazy-initialized service clients
 	searchClient     *SearchClient
 	publicClient     *PublicClient
@@ -84,31 +115,92 @@ func NewClient(config Config) (*Client, error) {
 		httpClient: &http.Client{
 			Timeout: config.Timeout,
 		},
+		tracer:  config.Tracer,
+		metrics: config.Metrics,
+		logger:  config.Logger,
+	}
+
+	if c.logger == nil {
+		c.logger = slog.New(slog.DiscardHandler)
+	}
+
+	// Build the HTTP transport: hooks wrap the envelope logger, so it logs
+	// what is actually sent
+	transport := config.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	if config.Logger != nil && config.LogEnvelopes {
+		transport = &envelopeLogger{next: transport, logger: c.logger}
+	}
+	for i := len(config.TransportHooks) - 1; i >= 0; i-- {
+		transport = config.TransportHooks[i](transport)
+	}
+	c.httpClient.Transport = transport
+
+	if c.tracer == nil {
+		c.tracer = NoopTracer{}
+	}
+	if c.metrics == nil {
+		c.metrics = NoopMetrics{}
 	}
 
 	// Initialize rate limiter if configured
-	if config.RateLimit > 0 {
-		c.rateLimiter = middleware.NewRateLimiter(config.RateLimit)
+	if config.Limiter != nil {
+		c.limiter = config.Limiter
+	} else if config.RateLimit > 0 {
+		rateLimiter := middleware.NewRateLimiter(config.RateLimit)
+		for priority, share := range config.RateLimitMinShare {
+			rateLimiter.SetMinShare(priority, share)
+		}
+		if config.AdaptiveRateLimit != nil {
+			rateLimiter.SetAdaptive(*config.AdaptiveRateLimit)
+		}
+		c.limiter = rateLimiter
+	}
+
+	// Initialize quota manager if configured
+	if len(config.Quotas) > 0 {
+		c.quota = middleware.NewQuotaManager(config.QuotaStore, config.Quotas)
 	}
 
-	// Initialize retryer if configured
+	// Initialize retryer if configured; the settings also apply to per-call WithRetry
+	c.retryConfig = middleware.RetryConfig{
+		MaxRetries:  config.MaxRetries,
+		BaseDelay:   config.RetryBaseDelay,
+		MaxDelay:    30 * time.Second,
+		Multiplier:  2.0,
+		Jitter:      0.2,
+		ShouldRetry: IsRetryable,
+	}
 	if config.RetryEnabled {
-		retryConfig := middleware.RetryConfig{
-			MaxRetries:  config.MaxRetries,
-			BaseDelay:   config.RetryBaseDelay,
-			MaxDelay:    30 * time.Second,
-			Multiplier:  2.0,
-			Jitter:      0.2,
-			ShouldRetry: IsRetryable,
-		}
-		c.retryer = middleware.NewRetryer(retryConfig)
+		c.retryer = middleware.NewRetryer(c.retryConfig)
 	}
 
 	// Initialize cache if configured
-	if config.CacheTTL > 0 {
+	if config.CacheStore != nil {
+		c.cache = config.CacheStore
+		if disk, ok := c.cache.(*middleware.DiskCache); ok && disk.OnError == nil {
+			c.cache = &loggingDiskCache{DiskCache: disk, logger: c.logger}
+		}
+	} else if config.CacheTTL > 0 {
 		c.cache = middleware.NewCache(config.CacheTTL)
+		c.ownsCache = true
 	}
 
+	// The loader coalesces concurrent calls for the same key even without a cache
+	c.loader = middleware.NewCacheLoader(c.cache, middleware.LoaderConfig{
+		TTL:                  config.CacheTTL,
+		StaleWhileRevalidate: config.CacheStaleWhileRevalidate,
+		RefreshTimeout:       config.Timeout,
+	})
+
+	interceptors := config.Interceptors
+	if interceptors == nil {
+		interceptors = DefaultInterceptors()
+	}
+	c.invoker = chainInterceptors(interceptors, c.send)
+
 	return c, nil
 }
 
@@ -146,7 +238,7 @@ func (c *Client) Listing() *ListingClient {
 }
 
 // Restricted returns the RestrictedClient for seller operations.
-// Requires user authentication (UserID and Token in config).
+// Requires user authentication (UserID and Token in config, a CredentialProvider, or WithUser per call).
 func (c *Client) Restricted() *RestrictedClient {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -158,7 +250,7 @@ func (c *Client) Restricted() *RestrictedClient {
 }
 
 // Order returns the OrderClient for order management operations.
-// Requires user authentication (UserID and Token in config).
+// Requires user authentication (UserID and Token in config, a CredentialProvider, or WithUser per call).
 func (c *Client) Order() *OrderClient {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -170,7 +262,7 @@ func (c *Client) Order() *OrderClient {
 }
 
 // Buyer returns the BuyerClient for buyer operations.
-// Requires user authentication (UserID and Token in config).
+// Requires user authentication (UserID and Token in config, a CredentialProvider, or WithUser per call).
 func (c *Client) Buyer() *BuyerClient {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -186,16 +278,78 @@ func (c *Client) Config() Config {
 	return c.config
 }
 
+// Limiter returns the client's rate limiter, or nil if rate limiting is disabled.
+func (c *Client) Limiter() middleware.Limiter {
+	return c.limiter
+}
+
+// RateLimiter returns the client's in-process rate limiter, or nil if rate
+// limiting is disabled or a different Limiter is configured. It can be used
+// to change the rate at runtime with SetRate.
+func (c *Client) RateLimiter() *middleware.RateLimiter {
+	rateLimiter, _ := c.limiter.(*middleware.RateLimiter)
+	return rateLimiter
+}
+
+// CacheStats returns the statistics of the client's cache.
+// Returns zero stats if caching is disabled.
+func (c *Client) CacheStats() middleware.CacheStats {
+	if c.cache == nil {
+		return middleware.CacheStats{}
+	}
+	return c.cache.Stats()
+}
+
+// QuotaUsage returns the current usage of the quota configured for service
+// (or middleware.QuotaAllServices). Returns zero usage if no quota is configured.
+func (c *Client) QuotaUsage(service string) middleware.QuotaUsage {
+	if c.quota == nil {
+		return middleware.QuotaUsage{Service: service}
+	}
+	return c.quota.Usage(service)
+}
+
 // Close releases any resources held by the client.
+// A cache store passed in through Config.CacheStore is left open.
 func (c *Client) Close() {
-	if c.cache != nil {
+	if c.cache != nil && c.ownsCache {
 		c.cache.Close()
 	}
 }
 
+// retryerFor returns the retryer for a call, or nil if it must not be retried.
+func (c *Client) retryerFor(options callOptions) *middleware.Retryer {
+	switch {
+	case options.retries < 0:
+		return c.retryer
+	case options.retries == 0:
+		return nil
+	}
+	retryConfig := c.retryConfig
+	retryConfig.MaxRetries = options.retries
+	return middleware.NewRetryer(retryConfig)
+}
+
+// configAuth returns the user authorization from the config, or nil if none is configured.
+func (c *Client) configAuth() *AuthorizationHeader {
+	if !c.config.HasUserAuth() {
+		return nil
+	}
+	return &AuthorizationHeader{UserID: c.config.UserID, Token: c.config.Token}
+}
+
+// serviceURL returns the endpoint of a service, relative to Config.BaseURL.
+func (c *Client) serviceURL(serviceURL string) string {
+	if base := c.config.baseURL(); base != DefaultBaseURL {
+		return base + strings.TrimPrefix(serviceURL, DefaultBaseURL)
+	}
+	return serviceURL
+}
+
 // createSOAPClient creates a new SOAP client for the given service URL.
-func (c *Client) createSOAPClient(serviceURL string) *soap.Client {
-	client := soap.NewClient(serviceURL, soap.WithHTTPClient(c.httpClient))
+// If auth is non-nil it is sent as the user authorization header.
+func (c *Client) createSOAPClient(serviceURL string, auth *AuthorizationHeader) *soap.Client {
+	client := soap.NewClient(c.serviceURL(serviceURL), soap.WithHTTPClient(c.httpClient))
 
 	// Add authentication headers
 	client.AddHeader(AuthenticationHeader{
@@ -204,48 +358,147 @@ func (c *Client) createSOAPClient(serviceURL string) *soap.Client {
 	})
 
 	// Add user authorization header if configured
-	if c.config.HasUserAuth() {
-		client.AddHeader(AuthorizationHeader{
-			UserID: c.config.UserID,
-			Token:  c.config.Token,
-		})
+	if auth != nil {
+		client.AddHeader(*auth)
 	}
 
 	return client
 }
 
-// executeWithMiddleware executes a function with rate limiting and retry support.
-func (c *Client) executeWithMiddleware(ctx context.Context, fn func() error) error {
-	// Apply rate limiting
-	if c.rateLimiter != nil {
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return err
-		}
+// executeWithMiddleware executes a function through the interceptor chain.
+func (c *Client) executeWithMiddleware(ctx context.Context, call Call, fn func(context.Context) error) error {
+	_, err := executeWithMiddlewareResult(c, ctx, call, func(ctx context.Context) (struct{}, error) {
+		return struct{}{}, fn(ctx)
+	})
+	return err
+}
+
+// executeWithMiddlewareResult executes a function that returns a result through
+// the interceptor chain. Each call is traced as one span and reported to the
+// metrics hooks.
+func executeWithMiddlewareResult[T any](c *Client, ctx context.Context, call Call, fn func(context.Context) (T, error)) (T, error) {
+	call.options = resolveCallOptions(ctx, call.opts)
+	call.Attempt = 1
+	call.client = c
+	call.stats = &callStats{}
+	call.do = func(ctx context.Context) (any, error) {
+		return fn(ctx)
 	}
 
-	// Apply retry logic
-	if c.retryer != nil {
-		return c.retryer.Do(ctx, fn)
+	// Start the span first so credential failures are traced and counted too
+	ctx, span := c.tracer.Start(ctx, call.spanName(), call.attributes()...)
+	defer span.End()
+	start := time.Now()
+
+	auth, override, err := c.resolveAuth(ctx, &call)
+	if err == nil && call.userAuth && auth == nil {
+		err = ErrAuthRequired
+	}
+	if err != nil {
+		c.record(ctx, &call, span, time.Since(start), err)
+		var zero T
+		return zero, err
+	}
+	if override {
+		ctx = withAuth(ctx, auth)
+	}
+	if call.userAuth && call.CacheKey != "" {
+		// Keep users' cached results apart when one client serves many users
+		call.CacheKey = fmt.Sprintf("user:%d:%s", auth.UserID, call.CacheKey)
+	}
+	if auth != nil {
+		span.SetAttributes(Attr(AttrAuthUserID, auth.UserID))
 	}
 
-	return fn()
-}
+	if call.options.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, call.options.timeout)
+		defer cancel()
+	}
+	if call.options.hasPriority {
+		ctx = middleware.WithPriority(ctx, call.options.priority)
+	}
+
+	err = c.invoker(ctx, &call)
+	c.record(ctx, &call, span, time.Since(start), err)
 
-// executeWithMiddlewareResult executes a function that returns a result with middleware support.
-func executeWithMiddlewareResult[T any](c *Client, ctx context.Context, fn func() (T, error)) (T, error) {
 	var result T
+	if err == nil {
+		if response, ok := call.Response.(T); ok {
+			result = response
+		} else if call.Response != nil {
+			err = fmt.Errorf("tradera: %s returned %T, want %T", call.spanName(), call.Response, result)
+		}
+	}
+	return result, err
+}
+
+// callStats collects what happened during one operation.
+// Fields are atomic because a stale-while-revalidate refresh may still be
+// running after the operation returned.
+type callStats struct {
+	attempts      atomic.Int32
+	rateLimitWait atomic.Int64 // nanoseconds
+	cacheLookup   atomic.Bool
+	cacheHit      atomic.Bool
+}
 
-	// Apply rate limiting
-	if c.rateLimiter != nil {
-		if err := c.rateLimiter.Wait(ctx); err != nil {
-			return result, err
+// record reports a finished operation to the span, the metrics hooks and the logger.
+func (c *Client) record(ctx context.Context, call *Call, span Span, duration time.Duration, err error) {
+	stats := call.stats
+	attempts := int(stats.attempts.Load())
+	wait := time.Duration(stats.rateLimitWait.Load())
+
+	if c.logger.Enabled(ctx, slog.LevelDebug) {
+		attrs := []slog.Attr{
+			slog.String("service", call.Service),
+			slog.String("action", call.Action),
+			slog.Duration("duration", duration),
+			slog.Int("attempts", attempts),
+			slog.Duration("rate_limit_wait", wait),
 		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error_type", errorType(err)), slog.Any("error", err))
+		}
+		c.logger.LogAttrs(ctx, slog.LevelDebug, "tradera: call", attrs...)
 	}
 
-	// Apply retry logic
-	if c.retryer != nil {
-		return middleware.DoWithResult(ctx, c.retryer, fn)
+	span.SetAttributes(
+		Attr(AttrRetryAttempts, attempts),
+		Attr(AttrRateLimitWait, wait.Milliseconds()),
+	)
+	if stats.cacheLookup.Load() {
+		span.SetAttributes(Attr(AttrCacheHit, stats.cacheHit.Load()))
+		c.metrics.ObserveCache(call.Service, call.Action, stats.cacheHit.Load())
+	}
+	if err != nil {
+		span.SetAttributes(Attr(AttrErrorType, errorType(err)))
+		span.RecordError(err)
 	}
 
-	return fn()
+	c.metrics.ObserveCall(call.Service, call.Action, duration, errorType(err))
+	if attempts > 1 {
+		c.metrics.ObserveRetries(call.Service, call.Action, attempts-1)
+	}
+	if c.limiter != nil {
+		c.metrics.ObserveRateLimitWait(call.Service, call.Action, wait)
+		if bucket, ok := c.limiter.(interface{ Available() float64 }); ok {
+			c.metrics.ObserveRateLimitTokens(bucket.Available())
+		}
+	}
+	if c.quota != nil {
+		remaining := -1
+		for key := range c.quota.Limits() {
+			if key == call.Service || key == middleware.QuotaAllServices {
+				usage := c.quota.Usage(key)
+				c.metrics.ObserveQuota(usage)
+				if remaining < 0 || usage.Remaining < remaining {
+					remaining = usage.Remaining
+				}
+			}
+		}
+		if remaining >= 0 {
+			span.SetAttributes(Attr(AttrQuotaRemaining, remaining))
+		}
+	}
 }
@@ -0,0 +1,129 @@
+package tradera
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// serviceURLs maps service names to their endpoints.
+var serviceURLs = map[string]string{
+	ServiceSearch:     SearchServiceURL,
+	ServicePublic:     PublicServiceURL,
+	ServiceListing:    ListingServiceURL,
+	ServiceRestricted: RestrictedServiceURL,
+	ServiceOrder:      OrderServiceURL,
+	ServiceBuyer:      BuyerServiceURL,
+}
+
+// VerifyReport describes what a client can do, as probed by Client.Verify.
+type VerifyReport struct {
+	// AppAuth is true if the AppID and AppKey were accepted.
+	AppAuth    bool
+	AppAuthErr error
+
+	// UserAuth is true if the user token was accepted. UserAuthErr is
+	// ErrAuthRequired if no user is configured for the probe.
+	UserAuth    bool
+	UserAuthErr error
+
+	// Services holds the reachability of each service by name
+	// (ServiceSearch, ...); a nil error means reachable.
+	Services map[string]error
+
+	// ServerTime is Tradera's official time and ClockSkew how far it is
+	// ahead of the local clock (negative if behind).
+	ServerTime time.Time
+	ClockSkew  time.Duration
+}
+
+// Reachable reports whether service responded to the probe.
+func (r *VerifyReport) Reachable(service string) bool {
+	err, ok := r.Services[service]
+	return ok && err == nil
+}
+
+// Err returns the problems found, joined, or nil if everything works.
+// A missing user configuration is not a problem.
+func (r *VerifyReport) Err() error {
+	var errs []error
+	if r.AppAuthErr != nil {
+		errs = append(errs, fmt.Errorf("app auth: %w", r.AppAuthErr))
+	}
+	if r.UserAuthErr != nil && !errors.Is(r.UserAuthErr, ErrAuthRequired) {
+		errs = append(errs, fmt.Errorf("user auth: %w", r.UserAuthErr))
+	}
+
+	services := make([]string, 0, len(r.Services))
+	for service := range r.Services {
+		services = append(services, service)
+	}
+	sort.Strings(services)
+	for _, service := range services {
+		if err := r.Services[service]; err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", service, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Verify probes the API with cheap calls and reports whether the app and
+// user credentials are valid, which services are reachable and how far the
+// server clock is skewed. App auth is probed with GetOfficialTime and user
+// auth with GetUserInfo, for the user ctx resolves to. Probes are not retried.
+func (c *Client) Verify(ctx context.Context) *VerifyReport {
+	report := &VerifyReport{Services: make(map[string]error, len(serviceURLs))}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for service, serviceURL := range serviceURLs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			err := c.probeService(ctx, serviceURL)
+			mu.Lock()
+			report.Services[service] = err
+			mu.Unlock()
+		}()
+	}
+
+	start := time.Now()
+	serverTime, err := c.Public().GetOfficialTime(ctx, WithRetry(0))
+	if err == nil {
+		rtt := time.Since(start)
+		report.AppAuth = true
+		report.ServerTime = inTraderaZone(serverTime)
+		report.ClockSkew = report.ServerTime.Sub(start.Add(rtt / 2))
+	} else {
+		report.AppAuthErr = err
+	}
+
+	_, err = c.Restricted().GetUserInfo(ctx, WithRetry(0))
+	report.UserAuth = err == nil
+	report.UserAuthErr = err
+
+	wg.Wait()
+	return report
+}
+
+// probeService checks that the service answers with its WSDL.
+func (c *Client) probeService(ctx context.Context, serviceURL string) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.serviceURL(serviceURL)+"?WSDL", nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return mapError("Verify", err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("tradera: unexpected HTTP status %s", resp.Status)
+	}
+	return nil
+}